@@ -0,0 +1,54 @@
+package filething
+
+import (
+	"fmt"
+	"os"
+)
+
+func (fileThing FileThing) Chmod(mode os.FileMode) error {
+	if fileThing.dryRun("chmod") {
+		return nil
+	}
+	if err := fileThing.chmod(fileThing.Path, mode); err != nil {
+		return &PathError{Op: "chmod", Path: fileThing.Path, Err: err}
+	}
+	return nil
+}
+
+func (fileThing FileThing) Chown(uid, gid int) error {
+	if fileThing.dryRun("chown") {
+		return nil
+	}
+	if err := fileThing.chown(fileThing.Path, uid, gid); err != nil {
+		return &PathError{Op: "chown", Path: fileThing.Path, Err: err}
+	}
+	return nil
+}
+
+func (fileThing FileThing) Touch() error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("touch") {
+		return nil
+	}
+	now := fileThing.now()
+	err := fileThing.chtimes(fileThing.Path, now, now)
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	file, err := fileThing.openFile(fileThing.Path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func (fileThing FileThing) Truncate(size int64) error {
+	if size < 0 {
+		return &PathError{Op: "truncate", Path: fileThing.Path, Err: fmt.Errorf("negative size %d", size)}
+	}
+	if fileThing.dryRun("truncate") {
+		return nil
+	}
+	return fileThing.truncate(fileThing.Path, size)
+}
@@ -0,0 +1,349 @@
+package filething
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Chmod", func() {
+		var chmodErr error
+
+		JustBeforeEach(func() {
+			chmodErr = fileThing.Chmod(0600)
+		})
+
+		It("does not return an error", func() {
+			Expect(chmodErr).NotTo(HaveOccurred())
+		})
+
+		It("changes the file permissions", func() {
+			info, err := os.Stat(someFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		Context("when the permission setter is stubbed", func() {
+			var (
+				chmodPath string
+				chmodMode os.FileMode
+			)
+
+			BeforeEach(func() {
+				fileThing.chmod = func(path string, mode os.FileMode) error {
+					chmodPath, chmodMode = path, mode
+					return nil
+				}
+			})
+
+			It("passes through FileThing.Path", func() {
+				Expect(chmodPath).To(Equal(someFile))
+			})
+
+			It("passes through the exact mode", func() {
+				Expect(chmodMode).To(Equal(os.FileMode(0600)))
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(chmodErr, os.ErrNotExist)).To(BeTrue())
+			})
+		})
+
+		Context("when changing permissions fails", func() {
+			BeforeEach(func() {
+				fileThing.chmod = failToChmod
+			})
+
+			It("returns an error", func() {
+				Expect(chmodErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(chmodErr).To(MatchError("chmod " + someFile + ": I failed"))
+			})
+
+			It("wraps the failure in a PathError", func() {
+				var pathErr *PathError
+				Expect(errors.As(chmodErr, &pathErr)).To(BeTrue())
+				Expect(pathErr.Op).To(Equal("chmod"))
+				Expect(pathErr.Path).To(Equal(someFile))
+			})
+		})
+	})
+
+	Describe("#Chown", func() {
+		var (
+			uid, gid  int
+			chownPath string
+			chownUID  int
+			chownGID  int
+			chownErr  error
+		)
+
+		BeforeEach(func() {
+			uid, gid = 1000, 1001
+			fileThing.chown = func(path string, uid, gid int) error {
+				chownPath, chownUID, chownGID = path, uid, gid
+				return nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			chownErr = fileThing.Chown(uid, gid)
+		})
+
+		It("does not return an error", func() {
+			Expect(chownErr).NotTo(HaveOccurred())
+		})
+
+		It("passes through FileThing.Path", func() {
+			Expect(chownPath).To(Equal(someFile))
+		})
+
+		It("passes through the uid and gid", func() {
+			Expect(chownUID).To(Equal(1000))
+			Expect(chownGID).To(Equal(1001))
+		})
+
+		Context("when -1 is given to leave an attribute unchanged", func() {
+			BeforeEach(func() {
+				uid, gid = -1, -1
+			})
+
+			It("forwards the sentinel values untouched", func() {
+				Expect(chownUID).To(Equal(-1))
+				Expect(chownGID).To(Equal(-1))
+			})
+		})
+
+		Context("when changing ownership is not permitted", func() {
+			BeforeEach(func() {
+				fileThing.chown = func(path string, uid, gid int) error {
+					return &os.PathError{Op: "chown", Path: path, Err: syscall.EPERM}
+				}
+			})
+
+			It("returns a permission error", func() {
+				Expect(errors.Is(chownErr, os.ErrPermission)).To(BeTrue())
+			})
+
+			It("reports the correct error", func() {
+				Expect(chownErr).To(MatchError("chown " + someFile + ": chown " + someFile + ": operation not permitted"))
+			})
+		})
+	})
+
+	Describe("#Touch", func() {
+		var (
+			touchErr error
+			before   time.Time
+		)
+
+		BeforeEach(func() {
+			before = time.Now().Add(-time.Hour)
+			err := os.Chtimes(someFile, before, before)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			touchErr = fileThing.Touch()
+		})
+
+		It("does not return an error", func() {
+			Expect(touchErr).NotTo(HaveOccurred())
+		})
+
+		It("updates the modification time", func() {
+			info, err := os.Stat(someFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.ModTime()).To(BeTemporally(">", before))
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not return an error", func() {
+				Expect(touchErr).NotTo(HaveOccurred())
+			})
+
+			It("creates an empty file", func() {
+				info, err := os.Stat(someFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Size()).To(BeZero())
+			})
+
+			Context("and the opener is stubbed", func() {
+				var (
+					file     *fakeWriteCloser
+					openFlag int
+				)
+
+				BeforeEach(func() {
+					file = new(fakeWriteCloser)
+					fileThing.openFile = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+						openFlag = flag
+						return file, nil
+					}
+				})
+
+				It("creates FileThing.Path", func() {
+					Expect(openFlag & os.O_CREATE).NotTo(BeZero())
+					Expect(file.closed).To(BeTrue())
+				})
+			})
+
+			Context("and creating FileThing.Path fails", func() {
+				BeforeEach(func() {
+					fileThing.openFile = failToOpenFile
+				})
+
+				It("reports the correct error", func() {
+					Expect(touchErr).To(MatchError("I failed"))
+				})
+			})
+		})
+
+		Context("when changing times fails", func() {
+			BeforeEach(func() {
+				fileThing.chtimes = failToChtimes
+			})
+
+			It("returns an error", func() {
+				Expect(touchErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(touchErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Truncate", func() {
+		var (
+			size        int64
+			truncateErr error
+		)
+
+		BeforeEach(func() {
+			size = 4
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			truncateErr = fileThing.Truncate(size)
+		})
+
+		It("does not return an error", func() {
+			Expect(truncateErr).NotTo(HaveOccurred())
+		})
+
+		It("shrinks the file", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some")))
+		})
+
+		Context("when size is larger than the file", func() {
+			BeforeEach(func() {
+				size = 16
+			})
+
+			It("zero-extends the file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents\x00\x00\x00")))
+			})
+		})
+
+		Context("when the truncator is stubbed", func() {
+			var truncateSize int64
+
+			BeforeEach(func() {
+				fileThing.truncate = func(path string, size int64) error {
+					truncateSize = size
+					return nil
+				}
+			})
+
+			It("forwards the exact size", func() {
+				Expect(truncateSize).To(Equal(int64(4)))
+			})
+		})
+
+		Context("when size is negative", func() {
+			var truncateCalled bool
+
+			BeforeEach(func() {
+				size = -1
+				truncateCalled = false
+				fileThing.truncate = func(string, int64) error {
+					truncateCalled = true
+					return nil
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(truncateErr).To(MatchError("truncate " + someFile + ": negative size -1"))
+			})
+
+			It("does not truncate the file", func() {
+				Expect(truncateCalled).To(BeFalse())
+			})
+		})
+
+		Context("when truncating fails", func() {
+			BeforeEach(func() {
+				fileThing.truncate = failToTruncate
+			})
+
+			It("returns an error", func() {
+				Expect(truncateErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(truncateErr).To(MatchError("I failed"))
+			})
+		})
+	})
+})
+
+func failToTruncate(path string, size int64) error {
+	return errors.New("I failed")
+}
+
+func failToChtimes(path string, atime, mtime time.Time) error {
+	return errors.New("I failed")
+}
+
+func failToChmod(path string, mode os.FileMode) error {
+	return errors.New("I failed")
+}
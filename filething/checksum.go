@@ -0,0 +1,38 @@
+package filething
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+func (fileThing FileThing) Checksum() (string, error) {
+	return fileThing.ChecksumWith(sha256.New())
+}
+
+func (fileThing FileThing) ChecksumWith(h hash.Hash) (string, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		return "", &PathError{Op: "checksum", Path: fileThing.Path, Err: err}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (fileThing FileThing) VerifyChecksum(expected string) error {
+	actual, err := fileThing.Checksum()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, expected) {
+		return &PathError{Op: "verify", Path: fileThing.Path, Err: fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)}
+	}
+	return nil
+}
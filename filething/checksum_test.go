@@ -0,0 +1,239 @@
+package filething
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const helloWorldSHA256 = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Checksum", func() {
+		var (
+			checksum    string
+			checksumErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("hello world"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			checksum, checksumErr = fileThing.Checksum()
+		})
+
+		It("does not return an error", func() {
+			Expect(checksumErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the SHA-256 digest of the file", func() {
+			Expect(checksum).To(Equal(helloWorldSHA256))
+		})
+
+		Context("when the opener is stubbed", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("hello world")
+			})
+
+			It("returns the SHA-256 digest of the stubbed contents", func() {
+				Expect(checksum).To(Equal(helloWorldSHA256))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("returns an error", func() {
+				Expect(checksumErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(checksumErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(io.MultiReader(
+						strings.NewReader("hello"),
+						iotest.ErrReader(errors.New("I failed")),
+					)), nil
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(checksumErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(checksumErr).To(MatchError("checksum " + someFile + ": I failed"))
+			})
+
+			It("does not return a digest", func() {
+				Expect(checksum).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#ChecksumWith", func() {
+		var (
+			h           hash.Hash
+			checksum    string
+			checksumErr error
+		)
+
+		BeforeEach(func() {
+			h = md5.New()
+			fileThing.open = openString("hello world")
+		})
+
+		JustBeforeEach(func() {
+			checksum, checksumErr = fileThing.ChecksumWith(h)
+		})
+
+		It("does not return an error", func() {
+			Expect(checksumErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the digest from the given hash", func() {
+			Expect(checksum).To(Equal("5eb63bbbe01eeed093cb22bb8f5acdc3"))
+		})
+
+		Context("when the hash is SHA-256", func() {
+			BeforeEach(func() {
+				h = sha256.New()
+			})
+
+			It("matches Checksum", func() {
+				Expect(checksum).To(Equal(helloWorldSHA256))
+			})
+		})
+
+		Context("when the hash is SHA-1", func() {
+			BeforeEach(func() {
+				h = sha1.New()
+			})
+
+			It("returns the SHA-1 digest", func() {
+				Expect(checksum).To(Equal("2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(checksumErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(iotest.ErrReader(errors.New("I failed"))), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(checksumErr).To(MatchError("checksum " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#VerifyChecksum", func() {
+		var (
+			expected  string
+			verifyErr error
+		)
+
+		BeforeEach(func() {
+			expected = helloWorldSHA256
+			fileThing.open = openString("hello world")
+		})
+
+		JustBeforeEach(func() {
+			verifyErr = fileThing.VerifyChecksum(expected)
+		})
+
+		It("does not return an error", func() {
+			Expect(verifyErr).NotTo(HaveOccurred())
+		})
+
+		Context("when the expected digest is upper case", func() {
+			BeforeEach(func() {
+				expected = strings.ToUpper(helloWorldSHA256)
+			})
+
+			It("does not return an error", func() {
+				Expect(verifyErr).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the digest does not match", func() {
+			BeforeEach(func() {
+				expected = strings.Repeat("0", 64)
+			})
+
+			It("reports a mismatch", func() {
+				Expect(verifyErr).To(MatchError(ContainSubstring("verify " + someFile + ": checksum mismatch")))
+			})
+
+			It("includes both digests", func() {
+				Expect(verifyErr).To(MatchError(ContainSubstring(expected)))
+				Expect(verifyErr).To(MatchError(ContainSubstring(helloWorldSHA256)))
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile + ".missing")
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(verifyErr, os.ErrNotExist)).To(BeTrue())
+			})
+		})
+	})
+})
+
+func openString(contents string) Opener {
+	return func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(contents)), nil
+	}
+}
+
+func failToOpen(path string) (io.ReadCloser, error) {
+	return nil, errors.New("I failed")
+}
@@ -0,0 +1,46 @@
+package filething
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+type FileThings []FileThing
+
+func (fts FileThings) RemoveAll() error {
+	var errs []error
+	for _, fileThing := range fts {
+		if err := fileThing.Remove(); err != nil {
+			errs = append(errs, withRemovePath(fileThing.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withRemovePath leaves errors that already name their path alone, so that a
+// failed os.Remove doesn't read "remove x: remove x: ...".
+func withRemovePath(path string, err error) error {
+	var osPathErr *os.PathError
+	var pathErr *PathError
+	if errors.As(err, &osPathErr) || errors.As(err, &pathErr) {
+		return err
+	}
+	return &PathError{Op: "remove", Path: path, Err: err}
+}
+
+func (fts FileThings) CopyAll(destDir string) (FileThings, error) {
+	var (
+		copied = FileThings{}
+		errs   []error
+	)
+	for _, fileThing := range fts {
+		dest, err := fileThing.Copy(filepath.Join(destDir, fileThing.Base()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		copied = append(copied, dest)
+	}
+	return copied, errors.Join(errs...)
+}
@@ -0,0 +1,222 @@
+package filething
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThings", func() {
+	var (
+		fileThings   FileThings
+		removedPaths []string
+	)
+
+	recordRemove := func(path string) error {
+		removedPaths = append(removedPaths, path)
+		return nil
+	}
+
+	BeforeEach(func() {
+		removedPaths = nil
+		fileThings = FileThings{
+			New("first", WithRemover(recordRemove)),
+			New("second", WithRemover(recordRemove)),
+			New("third", WithRemover(recordRemove)),
+		}
+	})
+
+	Describe("#RemoveAll", func() {
+		var removeAllErr error
+
+		JustBeforeEach(func() {
+			removeAllErr = fileThings.RemoveAll()
+		})
+
+		It("does not return an error", func() {
+			Expect(removeAllErr).NotTo(HaveOccurred())
+		})
+
+		It("removes every member", func() {
+			Expect(removedPaths).To(Equal([]string{"first", "second", "third"}))
+		})
+
+		Context("when removing a member fails", func() {
+			BeforeEach(func() {
+				fileThings[1].remove = func(path string) error {
+					removedPaths = append(removedPaths, path)
+					return failToRemove(path)
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(removeAllErr).To(HaveOccurred())
+			})
+
+			It("reports the failing path", func() {
+				Expect(removeAllErr).To(MatchError("remove second: I failed"))
+			})
+
+			It("still attempts the other members", func() {
+				Expect(removedPaths).To(Equal([]string{"first", "second", "third"}))
+			})
+		})
+
+		Context("when removing a member fails with a path error", func() {
+			BeforeEach(func() {
+				fileThings[1].remove = func(path string) error {
+					return &os.PathError{Op: "remove", Path: path, Err: os.ErrPermission}
+				}
+			})
+
+			It("does not repeat the path", func() {
+				Expect(removeAllErr).To(MatchError("remove second: permission denied"))
+			})
+
+			It("keeps the underlying error", func() {
+				Expect(errors.Is(removeAllErr, os.ErrPermission)).To(BeTrue())
+			})
+		})
+
+		Context("when removing several members fails", func() {
+			BeforeEach(func() {
+				fileThings[0].remove = failToRemove
+				fileThings[2].remove = failToRemove
+			})
+
+			It("reports every failing path", func() {
+				Expect(removeAllErr).To(MatchError("remove first: I failed\nremove third: I failed"))
+			})
+		})
+
+		Context("when the collection is empty", func() {
+			BeforeEach(func() {
+				fileThings = FileThings{}
+			})
+
+			It("does not return an error", func() {
+				Expect(removeAllErr).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("#CopyAll", func() {
+		var (
+			destDir    string
+			copiedDsts []string
+			copied     FileThings
+			copyAllErr error
+		)
+
+		recordCopy := func(src, dst string) error {
+			copiedDsts = append(copiedDsts, dst)
+			return nil
+		}
+
+		BeforeEach(func() {
+			destDir = filepath.Join("some", "dest")
+			copiedDsts = nil
+			fileThings = FileThings{
+				New(filepath.Join("src", "first")),
+				New(filepath.Join("src", "second")),
+				New(filepath.Join("src", "third")),
+			}
+			for i := range fileThings {
+				fileThings[i].copy = recordCopy
+			}
+		})
+
+		JustBeforeEach(func() {
+			copied, copyAllErr = fileThings.CopyAll(destDir)
+		})
+
+		It("does not return an error", func() {
+			Expect(copyAllErr).NotTo(HaveOccurred())
+		})
+
+		It("copies every member into destDir preserving base names", func() {
+			Expect(copiedDsts).To(Equal([]string{
+				filepath.Join(destDir, "first"),
+				filepath.Join(destDir, "second"),
+				filepath.Join(destDir, "third"),
+			}))
+		})
+
+		It("returns FileThings for the copies", func() {
+			Expect(paths(copied)).To(Equal(copiedDsts))
+		})
+
+		Context("when copying the middle member fails", func() {
+			BeforeEach(func() {
+				fileThings[1].copy = failToCopy
+			})
+
+			It("reports the failing path", func() {
+				Expect(copyAllErr).To(MatchError("copy " + filepath.Join("src", "second") + ": I failed"))
+			})
+
+			It("still copies the other members", func() {
+				Expect(copiedDsts).To(Equal([]string{
+					filepath.Join(destDir, "first"),
+					filepath.Join(destDir, "third"),
+				}))
+			})
+
+			It("returns the successful copies", func() {
+				Expect(paths(copied)).To(Equal(copiedDsts))
+			})
+		})
+
+		Context("when copying several members fails", func() {
+			BeforeEach(func() {
+				fileThings[0].copy = failToCopy
+				fileThings[2].copy = failToCopy
+			})
+
+			It("reports every failing path", func() {
+				Expect(copyAllErr).To(MatchError("copy " + filepath.Join("src", "first") + ": I failed\ncopy " + filepath.Join("src", "third") + ": I failed"))
+			})
+		})
+
+		Context("when destDir is the members' own directory", func() {
+			var someDir string
+
+			BeforeEach(func() {
+				someDir = createSomeTempDir()
+				destDir = someDir
+				fileThings = FileThings{New(filepath.Join(someDir, "first"))}
+				Expect(ioutil.WriteFile(fileThings[0].Path, []byte("some contents"), 0644)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(someDir)
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyAllErr).To(MatchError(ContainSubstring("is the same file")))
+			})
+
+			It("leaves the contents intact", func() {
+				Expect(ioutil.ReadFile(fileThings[0].Path)).To(Equal([]byte("some contents")))
+			})
+		})
+
+		Context("when the collection is empty", func() {
+			BeforeEach(func() {
+				fileThings = FileThings{}
+			})
+
+			It("does not return an error", func() {
+				Expect(copyAllErr).NotTo(HaveOccurred())
+			})
+
+			It("returns an empty collection", func() {
+				Expect(copied).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package filething
+
+import (
+	"bytes"
+	"io"
+)
+
+func (fileThing FileThing) ContentEqual(other FileThing) (bool, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return false, &PathError{Op: "compare", Path: fileThing.Path, Err: err}
+	}
+	otherInfo, err := fileThing.stat(other.Path)
+	if err != nil {
+		return false, &PathError{Op: "compare", Path: other.Path, Err: err}
+	}
+	if info.Size() != otherInfo.Size() {
+		return false, nil
+	}
+
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return false, &PathError{Op: "compare", Path: fileThing.Path, Err: err}
+	}
+	defer file.Close()
+
+	otherFile, err := fileThing.open(other.Path)
+	if err != nil {
+		return false, &PathError{Op: "compare", Path: other.Path, Err: err}
+	}
+	defer otherFile.Close()
+
+	buffer := make([]byte, 32*1024)
+	otherBuffer := make([]byte, len(buffer))
+	for {
+		n, done, err := readChunk(file, buffer)
+		if err != nil {
+			return false, &PathError{Op: "compare", Path: fileThing.Path, Err: err}
+		}
+		otherN, otherDone, err := readChunk(otherFile, otherBuffer)
+		if err != nil {
+			return false, &PathError{Op: "compare", Path: other.Path, Err: err}
+		}
+
+		if !bytes.Equal(buffer[:n], otherBuffer[:otherN]) || done != otherDone {
+			return false, nil
+		}
+		if done {
+			return true, nil
+		}
+	}
+}
+
+func (fileThing FileThing) ByteDiffCount(other FileThing) (int64, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return 0, &PathError{Op: "bytediff", Path: fileThing.Path, Err: err}
+	}
+	defer file.Close()
+
+	otherFile, err := fileThing.open(other.Path)
+	if err != nil {
+		return 0, &PathError{Op: "bytediff", Path: other.Path, Err: err}
+	}
+	defer otherFile.Close()
+
+	var (
+		diff        int64
+		buffer      = make([]byte, 32*1024)
+		otherBuffer = make([]byte, len(buffer))
+	)
+	for {
+		n, done, err := readChunk(file, buffer)
+		if err != nil {
+			return 0, &PathError{Op: "bytediff", Path: fileThing.Path, Err: err}
+		}
+		otherN, otherDone, err := readChunk(otherFile, otherBuffer)
+		if err != nil {
+			return 0, &PathError{Op: "bytediff", Path: other.Path, Err: err}
+		}
+
+		common := n
+		if otherN < common {
+			common = otherN
+		}
+		for i := 0; i < common; i++ {
+			if buffer[i] != otherBuffer[i] {
+				diff++
+			}
+		}
+		diff += int64(n + otherN - 2*common)
+
+		if done && otherDone {
+			return diff, nil
+		}
+	}
+}
+
+func readChunk(r io.Reader, buffer []byte) (int, bool, error) {
+	n, err := io.ReadFull(r, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, true, nil
+	}
+	return n, false, err
+}
@@ -0,0 +1,272 @@
+package filething
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+		otherFile string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		otherFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		os.Remove(otherFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+		Expect(otherFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#ContentEqual", func() {
+		var (
+			equal    bool
+			equalErr error
+		)
+
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(someFile, []byte("some contents"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(otherFile, []byte("some contents"), 0644)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			equal, equalErr = fileThing.ContentEqual(New(otherFile))
+		})
+
+		It("does not return an error", func() {
+			Expect(equalErr).NotTo(HaveOccurred())
+		})
+
+		It("reports equal contents", func() {
+			Expect(equal).To(BeTrue())
+		})
+
+		Context("when the files are larger than a single chunk", func() {
+			BeforeEach(func() {
+				contents := []byte(strings.Repeat("some contents", 10000))
+				Expect(ioutil.WriteFile(someFile, contents, 0644)).To(Succeed())
+				Expect(ioutil.WriteFile(otherFile, contents, 0644)).To(Succeed())
+			})
+
+			It("reports equal contents", func() {
+				Expect(equal).To(BeTrue())
+			})
+		})
+
+		Context("when the sizes differ", func() {
+			var openCalled bool
+
+			BeforeEach(func() {
+				openCalled = false
+				fileThing.stat = func(path string) (os.FileInfo, error) {
+					if path == otherFile {
+						return fakeFileInfo{size: 5}, nil
+					}
+					return fakeFileInfo{size: 13}, nil
+				}
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					openCalled = true
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports different contents", func() {
+				Expect(equalErr).NotTo(HaveOccurred())
+				Expect(equal).To(BeFalse())
+			})
+
+			It("does not read either file", func() {
+				Expect(openCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the sizes match but the bytes differ", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(otherFile, []byte("some Contents"), 0644)).To(Succeed())
+			})
+
+			It("does not return an error", func() {
+				Expect(equalErr).NotTo(HaveOccurred())
+			})
+
+			It("reports different contents", func() {
+				Expect(equal).To(BeFalse())
+			})
+		})
+
+		Context("when the other file is missing", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(otherFile)).To(Succeed())
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(equalErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("reports which file is missing", func() {
+				Expect(equalErr).To(MatchError(ContainSubstring("compare " + otherFile + ": ")))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(path string) (io.ReadCloser, error) {
+					if path == someFile {
+						return ioutil.NopCloser(failingReader{}), nil
+					}
+					return os.Open(path)
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(equalErr).To(MatchError("compare " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(equalErr).To(MatchError("compare " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#ByteDiffCount", func() {
+		var (
+			contents map[string]string
+			diff     int64
+			diffErr  error
+		)
+
+		BeforeEach(func() {
+			contents = map[string]string{
+				someFile:  "some contents",
+				otherFile: "some contents",
+			}
+			fileThing.open = func(path string) (io.ReadCloser, error) {
+				return ioutil.NopCloser(strings.NewReader(contents[path])), nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			diff, diffErr = fileThing.ByteDiffCount(New(otherFile))
+		})
+
+		It("does not return an error", func() {
+			Expect(diffErr).NotTo(HaveOccurred())
+		})
+
+		It("returns zero for identical files", func() {
+			Expect(diff).To(BeZero())
+		})
+
+		Context("when the files are the same length with different bytes", func() {
+			BeforeEach(func() {
+				contents[otherFile] = "SOME contentZ"
+			})
+
+			It("counts each differing position", func() {
+				Expect(diff).To(Equal(int64(5)))
+			})
+		})
+
+		Context("when the other file is longer", func() {
+			BeforeEach(func() {
+				contents[otherFile] = "some contents and more"
+			})
+
+			It("counts every extra byte as a difference", func() {
+				Expect(diff).To(Equal(int64(9)))
+			})
+		})
+
+		Context("when FileThing.Path is longer and also differs", func() {
+			BeforeEach(func() {
+				contents[otherFile] = "Some"
+			})
+
+			It("counts differing positions and extra bytes", func() {
+				Expect(diff).To(Equal(int64(10)))
+			})
+		})
+
+		Context("when one file is empty", func() {
+			BeforeEach(func() {
+				contents[otherFile] = ""
+			})
+
+			It("counts every byte of the other", func() {
+				Expect(diff).To(Equal(int64(13)))
+			})
+		})
+
+		Context("when the files span several chunks", func() {
+			BeforeEach(func() {
+				contents[someFile] = strings.Repeat("a", 32*1024) + "b"
+				contents[otherFile] = strings.Repeat("a", 32*1024) + "c" + strings.Repeat("d", 32*1024)
+			})
+
+			It("counts across chunk boundaries", func() {
+				Expect(diff).To(Equal(int64(1 + 32*1024)))
+			})
+		})
+
+		Context("when reading the real files", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile)
+				Expect(ioutil.WriteFile(someFile, []byte("abc"), 0644)).To(Succeed())
+				Expect(ioutil.WriteFile(otherFile, []byte("abd"), 0644)).To(Succeed())
+			})
+
+			It("counts the differences", func() {
+				Expect(diff).To(Equal(int64(1)))
+			})
+		})
+
+		Context("when opening the other file fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(path string) (io.ReadCloser, error) {
+					if path == otherFile {
+						return nil, errors.New("I failed")
+					}
+					return ioutil.NopCloser(strings.NewReader("some contents")), nil
+				}
+			})
+
+			It("reports which file failed", func() {
+				Expect(diffErr).To(MatchError("bytediff " + otherFile + ": I failed"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(path string) (io.ReadCloser, error) {
+					if path == someFile {
+						return ioutil.NopCloser(failingReader{}), nil
+					}
+					return ioutil.NopCloser(strings.NewReader("some contents")), nil
+				}
+			})
+
+			It("reports which file failed", func() {
+				Expect(diffErr).To(MatchError("bytediff " + someFile + ": I failed"))
+			})
+		})
+	})
+})
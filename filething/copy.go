@@ -0,0 +1,352 @@
+package filething
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func (fileThing FileThing) Copy(dest string) (FileThing, error) {
+	if fileThing.dryRunLogf != nil {
+		fileThing.dryRunLogf("dry run: copy %s to %s", fileThing.Path, dest)
+		return fileThing.withPath(dest), nil
+	}
+	if fileThing.isSameFile(dest) {
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: fmt.Errorf("%s is the same file", dest)}
+	}
+	if err := fileThing.copy(fileThing.Path, dest); err != nil {
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	return fileThing.withPath(dest), nil
+}
+
+// isSameFile guards the copying methods, because copying a file onto itself truncates it
+// before anything is read.
+func (fileThing FileThing) isSameFile(dest string) bool {
+	srcInfo, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return false
+	}
+	destInfo, err := fileThing.stat(dest)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(srcInfo, destInfo)
+}
+
+func (fileThing FileThing) DuplicateInto(destDir string) (FileThing, error) {
+	if fileThing.dryRunLogf != nil {
+		return fileThing.Copy(filepath.Join(destDir, fileThing.Base()))
+	}
+	if err := fileThing.mkdirAll(destDir, 0755); err != nil {
+		return FileThing{}, &PathError{Op: "duplicate", Path: destDir, Err: err}
+	}
+
+	dest := filepath.Join(destDir, fileThing.Base())
+	_, err := fileThing.stat(dest)
+	if err == nil {
+		return FileThing{}, &PathError{Op: "duplicate", Path: dest, Err: errors.New("destination exists")}
+	}
+	if !os.IsNotExist(err) {
+		return FileThing{}, &PathError{Op: "duplicate", Path: dest, Err: err}
+	}
+	return fileThing.Copy(dest)
+}
+
+func (fileThing FileThing) CopyPreserving(dest string) (FileThing, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+
+	copied, err := fileThing.Copy(dest)
+	if err != nil || fileThing.dryRunLogf != nil {
+		return copied, err
+	}
+
+	if err := fileThing.chmod(dest, info.Mode().Perm()); err != nil {
+		return copied, &PathError{Op: "copy", Path: fileThing.Path, Err: fmt.Errorf("copied data to %s but failed to preserve attributes: %w", dest, err)}
+	}
+	if err := fileThing.chtimes(dest, info.ModTime(), info.ModTime()); err != nil {
+		return copied, &PathError{Op: "copy", Path: fileThing.Path, Err: fmt.Errorf("copied data to %s but failed to preserve attributes: %w", dest, err)}
+	}
+	return copied, nil
+}
+
+// CopyTree recreates symlinks as symlinks with the same target rather than
+// following them, so links that point outside the tree are never pulled in.
+func (fileThing FileThing) CopyTree(dest string) (FileThing, error) {
+	if fileThing.dryRun("copytree") {
+		return fileThing.withPath(dest), nil
+	}
+	if err := fileThing.checkOutsideTree(dest); err != nil {
+		return FileThing{}, &PathError{Op: "copytree", Path: fileThing.Path, Err: err}
+	}
+
+	var dirs []copiedDir
+	err := fileThing.walk(fileThing.Path, func(path string, info os.FileInfo, err error) error {
+		var rel string
+		if err == nil {
+			rel, err = filepath.Rel(fileThing.Path, path)
+		}
+		if err == nil {
+			err = fileThing.copyEntry(path, filepath.Join(dest, rel), info)
+		}
+		if err != nil {
+			return &PathError{Op: "copytree", Path: path, Err: err}
+		}
+		if info.IsDir() {
+			dirs = append(dirs, copiedDir{src: path, dst: filepath.Join(dest, rel), mode: info.Mode().Perm()})
+		}
+		return nil
+	})
+	if err == nil {
+		err = fileThing.restoreDirModes("copytree", dirs)
+	}
+	if err != nil {
+		return FileThing{}, err
+	}
+	return fileThing.withPath(dest), nil
+}
+
+// Mirror only copies files that are missing from destDir, differ in size or are
+// newer in the source; symlinks are skipped. Files deleted from the source are
+// left in destDir so that a mistaken delete never propagates into a backup.
+func (fileThing FileThing) Mirror(destDir string) error {
+	if fileThing.dryRun("mirror") {
+		return nil
+	}
+	if err := fileThing.checkOutsideTree(destDir); err != nil {
+		return &PathError{Op: "mirror", Path: fileThing.Path, Err: err}
+	}
+
+	var dirs []copiedDir
+	err := fileThing.walk(fileThing.Path, func(path string, info os.FileInfo, err error) error {
+		var rel string
+		if err == nil {
+			rel, err = filepath.Rel(fileThing.Path, path)
+		}
+		if err == nil {
+			err = fileThing.mirrorEntry(path, filepath.Join(destDir, rel), info)
+		}
+		if err != nil {
+			return &PathError{Op: "mirror", Path: path, Err: err}
+		}
+		if info.IsDir() {
+			dirs = append(dirs, copiedDir{src: path, dst: filepath.Join(destDir, rel), mode: info.Mode().Perm()})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return fileThing.restoreDirModes("mirror", dirs)
+}
+
+// checkOutsideTree rejects a destination inside FileThing.Path, which the walk
+// would otherwise descend into while it is still being written.
+func (fileThing FileThing) checkOutsideTree(dest string) error {
+	src, err := fileThing.abs(fileThing.Path)
+	if err != nil {
+		return err
+	}
+	target, err := fileThing.abs(dest)
+	if err != nil {
+		return err
+	}
+	if target == src || isBelow(target, src) {
+		return fmt.Errorf("destination %s is inside the source", dest)
+	}
+	return nil
+}
+
+type copiedDir struct {
+	src, dst string
+	mode     os.FileMode
+}
+
+// restoreDirModes runs once the walk is done, because copyEntry creates
+// directories writable so that a read-only source directory can still be
+// filled. Children are restored before their parents.
+func (fileThing FileThing) restoreDirModes(op string, dirs []copiedDir) error {
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := fileThing.chmod(dirs[i].dst, dirs[i].mode); err != nil {
+			return &PathError{Op: op, Path: dirs[i].src, Err: err}
+		}
+	}
+	return nil
+}
+
+func (fileThing FileThing) mirrorEntry(src, dst string, info os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	if info.Mode().IsRegular() {
+		destInfo, err := fileThing.stat(dst)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil && destInfo.Size() == info.Size() && !info.ModTime().After(destInfo.ModTime()) {
+			return nil
+		}
+	}
+	return fileThing.copyEntry(src, dst, info)
+}
+
+func (fileThing FileThing) copyEntry(src, dst string, info os.FileInfo) error {
+	mode := info.Mode()
+	switch {
+	case mode.IsDir():
+		return fileThing.mkdirAll(dst, mode.Perm()|0700)
+	case mode&os.ModeSymlink != 0:
+		target, err := fileThing.readlink(src)
+		if err != nil {
+			return err
+		}
+		return fileThing.symlink(target, dst)
+	case mode.IsRegular():
+		if err := fileThing.copy(src, dst); err != nil {
+			return err
+		}
+		return fileThing.chmod(dst, mode.Perm())
+	default:
+		return fmt.Errorf("unsupported file mode %s", mode)
+	}
+}
+
+func (fileThing FileThing) CopyWithProgress(dest string, progress func(bytesCopied int64)) (FileThing, error) {
+	if fileThing.dryRun("copy") {
+		return fileThing.withPath(dest), nil
+	}
+	if fileThing.isSameFile(dest) {
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: fmt.Errorf("%s is the same file", dest)}
+	}
+	source, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	defer source.Close()
+
+	destination, err := fileThing.create(dest)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	copied := fileThing.withPath(dest)
+
+	if _, err := io.Copy(&progressWriter{writer: destination, progress: progress}, source); err != nil {
+		destination.Close()
+		copied.Remove()
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	if err := destination.Close(); err != nil {
+		copied.Remove()
+		return FileThing{}, &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	return copied, nil
+}
+
+func (fileThing FileThing) CopyAndChecksum(dest string) (FileThing, string, error) {
+	if fileThing.dryRun("copy") {
+		return fileThing.withPath(dest), "", nil
+	}
+	if fileThing.isSameFile(dest) {
+		return FileThing{}, "", &PathError{Op: "copy", Path: fileThing.Path, Err: fmt.Errorf("%s is the same file", dest)}
+	}
+	source, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return FileThing{}, "", &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	defer source.Close()
+
+	destination, err := fileThing.create(dest)
+	if err != nil {
+		return FileThing{}, "", &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	copied := fileThing.withPath(dest)
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(destination, hash), source); err != nil {
+		destination.Close()
+		copied.Remove()
+		return FileThing{}, "", &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	if err := destination.Close(); err != nil {
+		copied.Remove()
+		return FileThing{}, "", &PathError{Op: "copy", Path: fileThing.Path, Err: err}
+	}
+	return copied, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (fileThing FileThing) Process(transform func(io.Reader) (io.Reader, error), dest string) (FileThing, error) {
+	if fileThing.dryRun("process") {
+		return fileThing.withPath(dest), nil
+	}
+	if fileThing.isSameFile(dest) {
+		return FileThing{}, &PathError{Op: "process", Path: fileThing.Path, Err: fmt.Errorf("%s is the same file", dest)}
+	}
+	source, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "process", Path: fileThing.Path, Err: err}
+	}
+	defer source.Close()
+
+	transformed, err := transform(source)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "process", Path: fileThing.Path, Err: err}
+	}
+
+	destination, err := fileThing.create(dest)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "process", Path: fileThing.Path, Err: err}
+	}
+	processed := fileThing.withPath(dest)
+
+	if _, err := io.Copy(destination, transformed); err != nil {
+		destination.Close()
+		processed.Remove()
+		return FileThing{}, &PathError{Op: "process", Path: fileThing.Path, Err: err}
+	}
+	if err := destination.Close(); err != nil {
+		processed.Remove()
+		return FileThing{}, &PathError{Op: "process", Path: fileThing.Path, Err: err}
+	}
+	return processed, nil
+}
+
+type progressWriter struct {
+	writer   io.Writer
+	written  int64
+	progress func(int64)
+}
+
+func (writer *progressWriter) Write(data []byte) (int, error) {
+	n, err := writer.writer.Write(data)
+	if n > 0 {
+		writer.written += int64(n)
+		writer.progress(writer.written)
+	}
+	return n, err
+}
+
+func copyFile(src, dst string) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(destination, source); err != nil {
+		destination.Close()
+		return err
+	}
+	return destination.Close()
+}
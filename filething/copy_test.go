@@ -0,0 +1,1173 @@
+package filething
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing/iotest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+		someDest  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		someDest = someFile + ".copy"
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		os.Remove(someDest)
+		Expect(someFile).NotTo(BeAnExistingFile())
+		Expect(someDest).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Copy", func() {
+		var (
+			copied  FileThing
+			copyErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			copied, copyErr = fileThing.Copy(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(copyErr).NotTo(HaveOccurred())
+		})
+
+		It("copies the file contents", func() {
+			Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("some contents")))
+		})
+
+		It("leaves the source in place", func() {
+			Expect(someFile).To(BeAnExistingFile())
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(copied.Path).To(Equal(someDest))
+		})
+
+		Context("when FileThing.Path is empty", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte{}, 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not return an error", func() {
+				Expect(copyErr).NotTo(HaveOccurred())
+			})
+
+			It("creates an empty destination", func() {
+				info, err := os.Stat(someDest)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Size()).To(BeZero())
+			})
+		})
+
+		Context("when the destination is FileThing.Path itself", func() {
+			BeforeEach(func() {
+				someDest = someFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": " + someDest + " is the same file"))
+			})
+
+			It("leaves the contents intact", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+			})
+		})
+
+		Context("when the destination is a hard link to FileThing.Path", func() {
+			BeforeEach(func() {
+				Expect(os.Link(someFile, someDest)).To(Succeed())
+			})
+
+			It("reports an error", func() {
+				Expect(copyErr).To(MatchError(ContainSubstring("is the same file")))
+			})
+
+			It("leaves the contents intact", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+			})
+		})
+
+		Context("when copying FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.copy = failToCopy
+			})
+
+			It("returns an error", func() {
+				Expect(copyErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when the copier reports a permission error", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someDest, []byte("precious"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				fileThing.copy = func(string, string) error {
+					return &os.PathError{Op: "open", Path: someDest, Err: os.ErrPermission}
+				}
+			})
+
+			It("returns a permission error", func() {
+				Expect(errors.Is(copyErr, os.ErrPermission)).To(BeTrue())
+			})
+		})
+
+		Context("when FileThing.Path cannot be opened", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(someFile)).To(Succeed())
+				Expect(ioutil.WriteFile(someDest, []byte("precious"), 0644)).To(Succeed())
+			})
+
+			It("returns a not exist error", func() {
+				Expect(os.IsNotExist(errors.Unwrap(copyErr))).To(BeTrue())
+			})
+
+			It("leaves the destination untouched", func() {
+				Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("precious")))
+			})
+		})
+	})
+
+	Describe("#CopyWithProgress", func() {
+		var (
+			reports []int64
+			copied  FileThing
+			copyErr error
+		)
+
+		BeforeEach(func() {
+			reports = nil
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			copied, copyErr = fileThing.CopyWithProgress(someDest, func(bytesCopied int64) {
+				reports = append(reports, bytesCopied)
+			})
+		})
+
+		It("does not return an error", func() {
+			Expect(copyErr).NotTo(HaveOccurred())
+		})
+
+		It("copies the file contents", func() {
+			Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("some contents")))
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(copied.Path).To(Equal(someDest))
+		})
+
+		Context("when the destination is FileThing.Path itself", func() {
+			BeforeEach(func() {
+				someDest = someFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": " + someFile + " is the same file"))
+			})
+
+			It("leaves the contents intact", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+			})
+		})
+
+		It("reports the total size last", func() {
+			Expect(reports).NotTo(BeEmpty())
+			Expect(reports[len(reports)-1]).To(Equal(int64(13)))
+		})
+
+		Context("when the copy spans several buffers", func() {
+			var destination *fakeWriteCloser
+
+			BeforeEach(func() {
+				destination = new(fakeWriteCloser)
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(iotest.OneByteReader(strings.NewReader("some contents"))), nil
+				}
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return destination, nil
+				}
+			})
+
+			It("reports progress after each write", func() {
+				Expect(reports).To(HaveLen(13))
+				for i, report := range reports {
+					Expect(report).To(Equal(int64(i + 1)))
+				}
+			})
+
+			It("closes the destination", func() {
+				Expect(destination.closed).To(BeTrue())
+			})
+		})
+
+		Context("when writing fails part way through", func() {
+			var removedPath string
+
+			BeforeEach(func() {
+				removedPath = ""
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return &shortWriteCloser{limit: 4}, nil
+				}
+				fileThing.remove = func(path string) error {
+					removedPath = path
+					return nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+
+			It("never reports more than was actually copied", func() {
+				Expect(reports).NotTo(BeEmpty())
+				Expect(reports[len(reports)-1]).To(Equal(int64(4)))
+			})
+
+			It("removes the partial destination", func() {
+				Expect(removedPath).To(Equal(someDest))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+
+			It("does not report progress", func() {
+				Expect(reports).To(BeEmpty())
+			})
+		})
+
+		Context("when creating the destination fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#CopyAndChecksum", func() {
+		var (
+			copied   FileThing
+			checksum string
+			copyErr  error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("hello world"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			copied, checksum, copyErr = fileThing.CopyAndChecksum(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(copyErr).NotTo(HaveOccurred())
+		})
+
+		It("copies the file contents", func() {
+			Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("hello world")))
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(copied.Path).To(Equal(someDest))
+		})
+
+		Context("when the destination is FileThing.Path itself", func() {
+			BeforeEach(func() {
+				someDest = someFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": " + someFile + " is the same file"))
+			})
+
+			It("leaves the contents intact", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("hello world")))
+			})
+		})
+
+		It("returns the SHA-256 digest of the contents", func() {
+			Expect(checksum).To(Equal(helloWorldSHA256))
+		})
+
+		Context("when the source and destination are stubbed", func() {
+			var destination *fakeWriteCloser
+
+			BeforeEach(func() {
+				destination = new(fakeWriteCloser)
+				fileThing.open = openString("hello world")
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return destination, nil
+				}
+			})
+
+			It("writes the payload to the destination", func() {
+				Expect(destination.String()).To(Equal("hello world"))
+				Expect(destination.closed).To(BeTrue())
+			})
+
+			It("returns the digest of the payload", func() {
+				Expect(checksum).To(Equal(helloWorldSHA256))
+			})
+		})
+
+		Context("when writing fails part way through", func() {
+			var removedPath string
+
+			BeforeEach(func() {
+				removedPath = ""
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return &shortWriteCloser{limit: 4}, nil
+				}
+				fileThing.remove = func(path string) error {
+					removedPath = path
+					return nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+
+			It("does not return a digest", func() {
+				Expect(checksum).To(BeEmpty())
+			})
+
+			It("does not return a FileThing", func() {
+				Expect(copied).To(BeZero())
+			})
+
+			It("removes the partial destination", func() {
+				Expect(removedPath).To(Equal(someDest))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+
+			It("does not create the destination", func() {
+				Expect(someDest).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when creating the destination fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#Process", func() {
+		var (
+			transform  func(io.Reader) (io.Reader, error)
+			processed  FileThing
+			processErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+			transform = uppercase
+		})
+
+		JustBeforeEach(func() {
+			processed, processErr = fileThing.Process(transform, someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(processErr).NotTo(HaveOccurred())
+		})
+
+		It("writes the transformed contents to dest", func() {
+			Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("SOME CONTENTS")))
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(processed.Path).To(Equal(someDest))
+		})
+
+		Context("when the destination is FileThing.Path itself", func() {
+			BeforeEach(func() {
+				someDest = someFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(processErr).To(MatchError("process " + someFile + ": " + someFile + " is the same file"))
+			})
+
+			It("leaves the contents intact", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+			})
+		})
+
+		It("leaves the source untouched", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+		})
+
+		Context("when the source and destination are stubbed", func() {
+			var destination *fakeWriteCloser
+
+			BeforeEach(func() {
+				destination = new(fakeWriteCloser)
+				fileThing.open = openString("hello world")
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return destination, nil
+				}
+			})
+
+			It("writes the transformed payload", func() {
+				Expect(destination.String()).To(Equal("HELLO WORLD"))
+				Expect(destination.closed).To(BeTrue())
+			})
+		})
+
+		Context("when the transform fails", func() {
+			var createCalled bool
+
+			BeforeEach(func() {
+				createCalled = false
+				transform = func(io.Reader) (io.Reader, error) {
+					return nil, errors.New("I failed")
+				}
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					createCalled = true
+					return new(fakeWriteCloser), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(processErr).To(MatchError("process " + someFile + ": I failed"))
+			})
+
+			It("does not create the destination", func() {
+				Expect(createCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the transformed stream fails part way through", func() {
+			var removedPath string
+
+			BeforeEach(func() {
+				removedPath = ""
+				transform = func(r io.Reader) (io.Reader, error) {
+					return io.MultiReader(io.LimitReader(r, 4), failingReader{}), nil
+				}
+				fileThing.remove = func(path string) error {
+					removedPath = path
+					return os.Remove(path)
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(processErr).To(MatchError("process " + someFile + ": I failed"))
+			})
+
+			It("removes the partial destination", func() {
+				Expect(removedPath).To(Equal(someDest))
+				Expect(someDest).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(processErr).To(MatchError("process " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when creating the destination fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(processErr).To(MatchError("process " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#CopyPreserving", func() {
+		var (
+			modTime time.Time
+			copied  FileThing
+			copyErr error
+		)
+
+		BeforeEach(func() {
+			modTime = time.Date(2017, time.March, 14, 15, 9, 26, 0, time.UTC)
+			Expect(ioutil.WriteFile(someFile, []byte("some contents"), 0644)).To(Succeed())
+			Expect(os.Chmod(someFile, 0600)).To(Succeed())
+			Expect(os.Chtimes(someFile, modTime, modTime)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			copied, copyErr = fileThing.CopyPreserving(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(copyErr).NotTo(HaveOccurred())
+		})
+
+		It("copies the file contents", func() {
+			Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("some contents")))
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(copied.Path).To(Equal(someDest))
+		})
+
+		It("preserves the permission bits", func() {
+			info, err := os.Stat(someDest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("preserves the modification time", func() {
+			info, err := os.Stat(someDest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.ModTime()).To(BeTemporally("==", modTime))
+		})
+
+		Context("when stat and metadata functions are stubbed", func() {
+			var (
+				chmodded map[string]os.FileMode
+				chtimed  map[string]time.Time
+			)
+
+			BeforeEach(func() {
+				chmodded, chtimed = map[string]os.FileMode{}, map[string]time.Time{}
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{mode: 0640, modTime: modTime}, nil
+				}
+				fileThing.copy = func(string, string) error {
+					return nil
+				}
+				fileThing.chmod = func(path string, mode os.FileMode) error {
+					chmodded[path] = mode
+					return nil
+				}
+				fileThing.chtimes = func(path string, atime, mtime time.Time) error {
+					chtimed[path] = mtime
+					return nil
+				}
+			})
+
+			It("applies the source's mode and mtime to dest", func() {
+				Expect(chmodded).To(Equal(map[string]os.FileMode{someDest: 0640}))
+				Expect(chtimed).To(Equal(map[string]time.Time{someDest: modTime}))
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+
+			It("does not copy", func() {
+				Expect(someDest).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when copying fails", func() {
+			BeforeEach(func() {
+				fileThing.copy = failToCopy
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when setting the mode fails", func() {
+			BeforeEach(func() {
+				fileThing.chmod = func(string, os.FileMode) error {
+					return errors.New("I failed")
+				}
+			})
+
+			It("reports that the data copied but the attributes did not", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": copied data to " + someDest + " but failed to preserve attributes: I failed"))
+			})
+
+			It("still returns a FileThing for the destination", func() {
+				Expect(copied.Path).To(Equal(someDest))
+				Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("some contents")))
+			})
+		})
+
+		Context("when setting the modification time fails", func() {
+			BeforeEach(func() {
+				fileThing.chtimes = func(string, time.Time, time.Time) error {
+					return errors.New("I failed")
+				}
+			})
+
+			It("reports that the data copied but the attributes did not", func() {
+				Expect(copyErr).To(MatchError("copy " + someFile + ": copied data to " + someDest + " but failed to preserve attributes: I failed"))
+			})
+		})
+	})
+})
+
+func failToCopy(src, dst string) error {
+	return errors.New("I failed")
+}
+
+func uppercase(r io.Reader) (io.Reader, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(bytes.ToUpper(data)), nil
+}
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someDir   string
+		someSrc   string
+		someDest  string
+	)
+
+	BeforeEach(func() {
+		someDir = createSomeTempDir()
+		someSrc = filepath.Join(someDir, "src")
+		someDest = filepath.Join(someDir, "dest")
+		fileThing = New(someSrc)
+
+		Expect(os.MkdirAll(filepath.Join(someSrc, "sub"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(someSrc, "file"), []byte("some contents"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(someSrc, "sub", "secret"), []byte("secret"), 0600)).To(Succeed())
+		Expect(os.Symlink("../file", filepath.Join(someSrc, "sub", "link"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(someDir)
+		Expect(someDir).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#CopyTree", func() {
+		var (
+			copied  FileThing
+			copyErr error
+		)
+
+		JustBeforeEach(func() {
+			copied, copyErr = fileThing.CopyTree(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(copyErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(copied.Path).To(Equal(someDest))
+		})
+
+		It("copies the directory tree", func() {
+			Expect(ioutil.ReadFile(filepath.Join(someDest, "file"))).To(Equal([]byte("some contents")))
+			Expect(ioutil.ReadFile(filepath.Join(someDest, "sub", "secret"))).To(Equal([]byte("secret")))
+		})
+
+		It("preserves file modes", func() {
+			info, err := os.Stat(filepath.Join(someDest, "sub", "secret"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("copies symlinks as links rather than following them", func() {
+			info, err := os.Lstat(filepath.Join(someDest, "sub", "link"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode() & os.ModeSymlink).NotTo(BeZero())
+			Expect(os.Readlink(filepath.Join(someDest, "sub", "link"))).To(Equal("../file"))
+		})
+
+		Context("when a source directory is read-only", func() {
+			BeforeEach(func() {
+				Expect(os.Chmod(filepath.Join(someSrc, "sub"), 0555)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				os.Chmod(filepath.Join(someSrc, "sub"), 0755)
+				os.Chmod(filepath.Join(someDest, "sub"), 0755)
+			})
+
+			It("still copies its contents", func() {
+				Expect(copyErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(filepath.Join(someDest, "sub", "secret"))).To(Equal([]byte("secret")))
+			})
+
+			It("gives the copy the same mode", func() {
+				info, err := os.Stat(filepath.Join(someDest, "sub"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0555)))
+			})
+		})
+
+		Context("when the destination is FileThing.Path", func() {
+			BeforeEach(func() {
+				someDest = someSrc
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copytree " + someSrc + ": destination " + someSrc + " is inside the source"))
+			})
+
+			It("leaves the files intact", func() {
+				Expect(ioutil.ReadFile(filepath.Join(someSrc, "file"))).To(Equal([]byte("some contents")))
+			})
+		})
+
+		Context("when the destination is inside FileThing.Path", func() {
+			BeforeEach(func() {
+				someDest = filepath.Join(someSrc, "sub", "copy")
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copytree " + someSrc + ": destination " + someDest + " is inside the source"))
+			})
+
+			It("does not create the destination", func() {
+				Expect(someDest).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the tree is synthetic", func() {
+			var (
+				madeDirs    []string
+				madePerms   []os.FileMode
+				chmodded    []string
+				copiedFiles []string
+				links       []string
+			)
+
+			BeforeEach(func() {
+				madeDirs, madePerms, chmodded, copiedFiles, links = nil, nil, nil, nil, nil
+
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					entries := []fakeFileInfo{
+						{name: "src", mode: os.ModeDir | 0555},
+						{name: "a", mode: 0644},
+						{name: "b", mode: 0644},
+						{name: "link", mode: os.ModeSymlink | 0777},
+					}
+					for i, entry := range entries {
+						path := root
+						if i > 0 {
+							path = filepath.Join(root, entry.name)
+						}
+						if err := walkFn(path, entry, nil); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+				fileThing.mkdirAll = func(path string, perm os.FileMode) error {
+					madeDirs = append(madeDirs, path)
+					madePerms = append(madePerms, perm)
+					return nil
+				}
+				fileThing.copy = func(src, dst string) error {
+					copiedFiles = append(copiedFiles, dst)
+					return nil
+				}
+				fileThing.chmod = func(path string, mode os.FileMode) error {
+					chmodded = append(chmodded, fmt.Sprintf("%s %o", path, mode))
+					return nil
+				}
+				fileThing.readlink = func(string) (string, error) {
+					return "a", nil
+				}
+				fileThing.symlink = func(oldname, newname string) error {
+					links = append(links, oldname+" -> "+newname)
+					return nil
+				}
+			})
+
+			It("creates directories, copies files and recreates links", func() {
+				Expect(madeDirs).To(Equal([]string{someDest}))
+				Expect(copiedFiles).To(Equal([]string{filepath.Join(someDest, "a"), filepath.Join(someDest, "b")}))
+				Expect(links).To(Equal([]string{"a -> " + filepath.Join(someDest, "link")}))
+			})
+
+			It("creates directories writable and applies their mode last", func() {
+				Expect(madePerms).To(Equal([]os.FileMode{0755}))
+				Expect(chmodded[len(chmodded)-1]).To(Equal(someDest + " 555"))
+			})
+
+			Context("and restoring a directory mode fails", func() {
+				BeforeEach(func() {
+					fileThing.chmod = func(path string, mode os.FileMode) error {
+						if path == someDest {
+							return errors.New("I failed")
+						}
+						return nil
+					}
+				})
+
+				It("reports which source path failed", func() {
+					Expect(copyErr).To(MatchError("copytree " + someSrc + ": I failed"))
+				})
+			})
+
+			Context("and copying a file part way through fails", func() {
+				BeforeEach(func() {
+					fileThing.copy = func(src, dst string) error {
+						copiedFiles = append(copiedFiles, dst)
+						if filepath.Base(src) == "a" {
+							return errors.New("I failed")
+						}
+						return nil
+					}
+				})
+
+				It("reports which source path failed", func() {
+					Expect(copyErr).To(MatchError("copytree " + filepath.Join(someSrc, "a") + ": I failed"))
+				})
+
+				It("stops copying", func() {
+					Expect(copiedFiles).To(Equal([]string{filepath.Join(someDest, "a")}))
+				})
+			})
+
+			Context("and creating a directory fails", func() {
+				BeforeEach(func() {
+					fileThing.mkdirAll = failToMkdirAll
+				})
+
+				It("reports which source path failed", func() {
+					Expect(copyErr).To(MatchError("copytree " + someSrc + ": I failed"))
+				})
+			})
+		})
+
+		Context("when the walker reports an error", func() {
+			BeforeEach(func() {
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					return walkFn(root, nil, errors.New("I failed"))
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(copyErr).To(MatchError("copytree " + someSrc + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#DuplicateInto", func() {
+		var (
+			destDir      string
+			duplicate    FileThing
+			duplicateErr error
+		)
+
+		BeforeEach(func() {
+			fileThing = New(filepath.Join(someSrc, "file"))
+			destDir = filepath.Join(someDir, "dest")
+			Expect(os.Mkdir(destDir, 0755)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			duplicate, duplicateErr = fileThing.DuplicateInto(destDir)
+		})
+
+		It("does not return an error", func() {
+			Expect(duplicateErr).NotTo(HaveOccurred())
+		})
+
+		It("copies the file into destDir under the same name", func() {
+			Expect(ioutil.ReadFile(filepath.Join(destDir, "file"))).To(Equal([]byte("some contents")))
+		})
+
+		It("returns a FileThing for the copy", func() {
+			Expect(duplicate.Path).To(Equal(filepath.Join(destDir, "file")))
+		})
+
+		Context("when destDir is missing", func() {
+			BeforeEach(func() {
+				destDir = filepath.Join(someDir, "missing", "dir")
+			})
+
+			It("creates it", func() {
+				Expect(destDir).To(BeADirectory())
+				Expect(ioutil.ReadFile(filepath.Join(destDir, "file"))).To(Equal([]byte("some contents")))
+			})
+		})
+
+		Context("when the destination already exists", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(filepath.Join(destDir, "file"), []byte("precious"), 0644)).To(Succeed())
+			})
+
+			It("reports the correct error", func() {
+				Expect(duplicateErr).To(MatchError("duplicate " + filepath.Join(destDir, "file") + ": destination exists"))
+			})
+
+			It("does not overwrite it", func() {
+				Expect(ioutil.ReadFile(filepath.Join(destDir, "file"))).To(Equal([]byte("precious")))
+			})
+		})
+
+		Context("when the copier is stubbed", func() {
+			var copiedTo string
+
+			BeforeEach(func() {
+				fileThing.copy = func(src, dst string) error {
+					copiedTo = dst
+					return nil
+				}
+			})
+
+			It("copies via the copier", func() {
+				Expect(copiedTo).To(Equal(filepath.Join(destDir, "file")))
+			})
+		})
+
+		Context("when creating destDir fails", func() {
+			BeforeEach(func() {
+				fileThing.mkdirAll = failToMkdirAll
+			})
+
+			It("reports the correct error", func() {
+				Expect(duplicateErr).To(MatchError("duplicate " + destDir + ": I failed"))
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(duplicateErr).To(MatchError("duplicate " + filepath.Join(destDir, "file") + ": I failed"))
+			})
+		})
+
+		Context("when copying fails", func() {
+			BeforeEach(func() {
+				fileThing.copy = failToCopy
+			})
+
+			It("reports the correct error", func() {
+				Expect(duplicateErr).To(MatchError("copy " + fileThing.Path + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#Mirror", func() {
+		var (
+			now         time.Time
+			sources     []fakeFileInfo
+			destination map[string]fakeFileInfo
+			madeDirs    []string
+			copiedFiles []string
+			mirrorErr   error
+		)
+
+		BeforeEach(func() {
+			now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			madeDirs, copiedFiles = nil, nil
+			sources = []fakeFileInfo{
+				{name: "src", mode: os.ModeDir | 0755},
+				{name: "new", size: 3, mode: 0644, modTime: now},
+				{name: "unchanged", size: 3, mode: 0644, modTime: now},
+				{name: "newer", size: 3, mode: 0644, modTime: now},
+				{name: "resized", size: 5, mode: 0644, modTime: now},
+				{name: "link", mode: os.ModeSymlink | 0777, modTime: now},
+			}
+			destination = map[string]fakeFileInfo{
+				filepath.Join(someDest, "unchanged"): {size: 3, modTime: now},
+				filepath.Join(someDest, "newer"):     {size: 3, modTime: now.Add(-time.Hour)},
+				filepath.Join(someDest, "resized"):   {size: 3, modTime: now},
+				filepath.Join(someDest, "deleted"):   {size: 3, modTime: now},
+			}
+
+			fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+				for i, entry := range sources {
+					path := root
+					if i > 0 {
+						path = filepath.Join(root, entry.name)
+					}
+					if err := walkFn(path, entry, nil); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			fileThing.stat = func(path string) (os.FileInfo, error) {
+				if info, ok := destination[path]; ok {
+					return info, nil
+				}
+				return statNotExist(path)
+			}
+			fileThing.mkdirAll = func(path string, perm os.FileMode) error {
+				madeDirs = append(madeDirs, path)
+				return nil
+			}
+			fileThing.copy = func(src, dst string) error {
+				copiedFiles = append(copiedFiles, filepath.Base(dst))
+				return nil
+			}
+			fileThing.chmod = func(string, os.FileMode) error {
+				return nil
+			}
+			fileThing.remove = failToRemove
+			fileThing.removeAll = failToRemove
+		})
+
+		JustBeforeEach(func() {
+			mirrorErr = fileThing.Mirror(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(mirrorErr).NotTo(HaveOccurred())
+		})
+
+		It("creates the destination directory", func() {
+			Expect(madeDirs).To(Equal([]string{someDest}))
+		})
+
+		It("copies files that are missing, newer or resized", func() {
+			Expect(copiedFiles).To(Equal([]string{"new", "newer", "resized"}))
+		})
+
+		It("does not recopy up-to-date files", func() {
+			Expect(copiedFiles).NotTo(ContainElement("unchanged"))
+		})
+
+		It("skips symlinks", func() {
+			Expect(copiedFiles).NotTo(ContainElement("link"))
+		})
+
+		It("leaves files deleted from the source in the destination", func() {
+			Expect(copiedFiles).NotTo(ContainElement("deleted"))
+			Expect(mirrorErr).NotTo(HaveOccurred())
+		})
+
+		Context("when the destination file is newer than the source", func() {
+			BeforeEach(func() {
+				destination[filepath.Join(someDest, "newer")] = fakeFileInfo{size: 3, modTime: now.Add(time.Hour)}
+			})
+
+			It("does not recopy it", func() {
+				Expect(copiedFiles).To(Equal([]string{"new", "resized"}))
+			})
+		})
+
+		Context("when stat fails for a destination file", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports which source path failed", func() {
+				Expect(mirrorErr).To(MatchError("mirror " + filepath.Join(someSrc, "new") + ": I failed"))
+			})
+
+			It("stops copying", func() {
+				Expect(copiedFiles).To(BeEmpty())
+			})
+		})
+
+		Context("when copying a file fails", func() {
+			BeforeEach(func() {
+				fileThing.copy = failToCopy
+			})
+
+			It("reports which source path failed", func() {
+				Expect(mirrorErr).To(MatchError("mirror " + filepath.Join(someSrc, "new") + ": I failed"))
+			})
+		})
+
+		Context("when the walker reports an error", func() {
+			BeforeEach(func() {
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					return walkFn(root, nil, errors.New("I failed"))
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(mirrorErr).To(MatchError("mirror " + someSrc + ": I failed"))
+			})
+		})
+
+		Context("when mirroring a real tree", func() {
+			BeforeEach(func() {
+				fileThing = New(someSrc)
+			})
+
+			It("copies the directory tree", func() {
+				Expect(ioutil.ReadFile(filepath.Join(someDest, "file"))).To(Equal([]byte("some contents")))
+				Expect(ioutil.ReadFile(filepath.Join(someDest, "sub", "secret"))).To(Equal([]byte("secret")))
+			})
+
+			It("does not recopy unchanged files on a second run", func() {
+				var recopied []string
+				fileThing.copy = func(src, dst string) error {
+					recopied = append(recopied, dst)
+					return copyFile(src, dst)
+				}
+
+				Expect(fileThing.Mirror(someDest)).To(Succeed())
+				Expect(recopied).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -0,0 +1,109 @@
+package filething
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+func (fileThing FileThing) Encrypt(key []byte, destPath string) (FileThing, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "encrypt", Path: fileThing.Path, Err: err}
+	}
+	if fileThing.dryRun("encrypt") {
+		return fileThing.withPath(destPath), nil
+	}
+
+	plaintext, err := fileThing.readAll()
+	if err != nil {
+		return FileThing{}, &PathError{Op: "encrypt", Path: fileThing.Path, Err: err}
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(fileThing.random, nonce); err != nil {
+		return FileThing{}, &PathError{Op: "encrypt", Path: fileThing.Path, Err: err}
+	}
+
+	encrypted, err := fileThing.createWith(destPath, aead.Seal(nonce, nonce, plaintext, nil))
+	if err != nil {
+		return FileThing{}, &PathError{Op: "encrypt", Path: fileThing.Path, Err: err}
+	}
+	return encrypted, nil
+}
+
+func (fileThing FileThing) Decrypt(key []byte, destPath string) (FileThing, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "decrypt", Path: fileThing.Path, Err: err}
+	}
+	if fileThing.dryRun("decrypt") {
+		return fileThing.withPath(destPath), nil
+	}
+
+	data, err := fileThing.readAll()
+	if err != nil {
+		return FileThing{}, &PathError{Op: "decrypt", Path: fileThing.Path, Err: err}
+	}
+	if len(data) < aead.NonceSize() {
+		return FileThing{}, &PathError{Op: "decrypt", Path: fileThing.Path, Err: errors.New("ciphertext too short")}
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "decrypt", Path: fileThing.Path, Err: fmt.Errorf("authentication failed: %w", err)}
+	}
+
+	decrypted, err := fileThing.createWith(destPath, plaintext)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "decrypt", Path: fileThing.Path, Err: err}
+	}
+	return decrypted, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("invalid key length %d: must be 16, 24 or 32 bytes", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (fileThing FileThing) readAll() ([]byte, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(file)
+}
+
+func (fileThing FileThing) createWith(path string, data []byte) (FileThing, error) {
+	destination, err := fileThing.create(path)
+	if err != nil {
+		return FileThing{}, err
+	}
+	created := fileThing.withPath(path)
+
+	if _, err := destination.Write(data); err != nil {
+		destination.Close()
+		created.Remove()
+		return FileThing{}, err
+	}
+	if err := destination.Close(); err != nil {
+		created.Remove()
+		return FileThing{}, err
+	}
+	return created, nil
+}
@@ -0,0 +1,284 @@
+package filething
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+		someDest  string
+		someKey   []byte
+		someNonce []byte
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		someDest = someFile + ".enc"
+		someKey = bytes.Repeat([]byte("k"), 32)
+		someNonce = bytes.Repeat([]byte("n"), 12)
+		fileThing = New(someFile)
+		fileThing.open = openString("some contents")
+		fileThing.random = bytes.NewReader(someNonce)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		os.Remove(someDest)
+		Expect(someFile).NotTo(BeAnExistingFile())
+		Expect(someDest).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Encrypt", func() {
+		var (
+			key         []byte
+			destination *fakeWriteCloser
+			encrypted   FileThing
+			encryptErr  error
+		)
+
+		BeforeEach(func() {
+			key = someKey
+			destination = new(fakeWriteCloser)
+			fileThing.create = func(string) (io.WriteCloser, error) {
+				return destination, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			encrypted, encryptErr = fileThing.Encrypt(key, someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(encryptErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(encrypted.Path).To(Equal(someDest))
+		})
+
+		It("prepends the nonce to the AES-GCM ciphertext", func() {
+			Expect(destination.Bytes()).To(Equal(append(someNonce, seal(someKey, someNonce, "some contents")...)))
+		})
+
+		It("closes the destination", func() {
+			Expect(destination.closed).To(BeTrue())
+		})
+
+		Context("when the key has the wrong length", func() {
+			var openCalled bool
+
+			BeforeEach(func() {
+				key = []byte("short")
+				openCalled = false
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					openCalled = true
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(encryptErr).To(MatchError("encrypt " + someFile + ": invalid key length 5: must be 16, 24 or 32 bytes"))
+			})
+
+			It("does not open FileThing.Path", func() {
+				Expect(openCalled).To(BeFalse())
+			})
+		})
+
+		Context("when generating the nonce fails", func() {
+			BeforeEach(func() {
+				fileThing.random = failingReader{}
+			})
+
+			It("reports the correct error", func() {
+				Expect(encryptErr).To(MatchError("encrypt " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when writing the destination fails", func() {
+			var removedPath string
+
+			BeforeEach(func() {
+				destination.writeErr = errors.New("I failed")
+				removedPath = ""
+				fileThing.remove = func(path string) error {
+					removedPath = path
+					return nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(encryptErr).To(MatchError("encrypt " + someFile + ": I failed"))
+			})
+
+			It("removes the partial destination", func() {
+				Expect(removedPath).To(Equal(someDest))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(encryptErr).To(MatchError("encrypt " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when creating the destination fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(encryptErr).To(MatchError("encrypt " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#Decrypt", func() {
+		var (
+			key        []byte
+			encrypted  []byte
+			plainFile  string
+			decrypted  FileThing
+			decryptErr error
+		)
+
+		BeforeEach(func() {
+			key = someKey
+			plainFile = someFile + ".plain"
+			encrypted = append(append([]byte{}, someNonce...), seal(someKey, someNonce, "some contents")...)
+		})
+
+		AfterEach(func() {
+			os.Remove(plainFile)
+		})
+
+		JustBeforeEach(func() {
+			Expect(ioutil.WriteFile(someDest, encrypted, 0644)).To(Succeed())
+			decrypted, decryptErr = New(someDest).Decrypt(key, plainFile)
+		})
+
+		It("does not return an error", func() {
+			Expect(decryptErr).NotTo(HaveOccurred())
+		})
+
+		It("recovers the plaintext", func() {
+			Expect(ioutil.ReadFile(plainFile)).To(Equal([]byte("some contents")))
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(decrypted.Path).To(Equal(plainFile))
+		})
+
+		Context("when round tripping through Encrypt", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(someFile, []byte("round trip"), 0644)).To(Succeed())
+				_, err := New(someFile).Encrypt(someKey, someFile+".tmp")
+				Expect(err).NotTo(HaveOccurred())
+				encrypted, err = ioutil.ReadFile(someFile + ".tmp")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.Remove(someFile + ".tmp")).To(Succeed())
+			})
+
+			It("recovers the plaintext", func() {
+				Expect(decryptErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(plainFile)).To(Equal([]byte("round trip")))
+			})
+		})
+
+		Context("when the ciphertext has been tampered with", func() {
+			BeforeEach(func() {
+				encrypted[len(encrypted)-1] ^= 0xff
+			})
+
+			It("reports an authentication failure", func() {
+				Expect(decryptErr).To(MatchError(ContainSubstring("decrypt " + someDest + ": authentication failed")))
+			})
+
+			It("does not write any output", func() {
+				Expect(plainFile).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the key is wrong", func() {
+			BeforeEach(func() {
+				key = bytes.Repeat([]byte("x"), 32)
+			})
+
+			It("reports an authentication failure", func() {
+				Expect(decryptErr).To(MatchError(ContainSubstring("authentication failed")))
+			})
+
+			It("does not write any output", func() {
+				Expect(plainFile).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when writing the plaintext fails", func() {
+			var removedPath string
+
+			JustBeforeEach(func() {
+				removedPath = ""
+				source := New(someDest)
+				source.create = func(string) (io.WriteCloser, error) {
+					return &shortWriteCloser{limit: 4}, nil
+				}
+				source.remove = func(path string) error {
+					removedPath = path
+					return nil
+				}
+				_, decryptErr = source.Decrypt(key, plainFile)
+			})
+
+			It("reports the correct error", func() {
+				Expect(decryptErr).To(MatchError("decrypt " + someDest + ": I failed"))
+			})
+
+			It("removes the partial destination", func() {
+				Expect(removedPath).To(Equal(plainFile))
+			})
+		})
+
+		Context("when the ciphertext is shorter than a nonce", func() {
+			BeforeEach(func() {
+				encrypted = []byte("short")
+			})
+
+			It("reports the correct error", func() {
+				Expect(decryptErr).To(MatchError("decrypt " + someDest + ": ciphertext too short"))
+			})
+		})
+
+		Context("when the key has the wrong length", func() {
+			BeforeEach(func() {
+				key = []byte("short")
+			})
+
+			It("reports the correct error", func() {
+				Expect(decryptErr).To(MatchError("decrypt " + someDest + ": invalid key length 5: must be 16, 24 or 32 bytes"))
+			})
+		})
+	})
+})
+
+func seal(key, nonce []byte, plaintext string) []byte {
+	block, err := aes.NewCipher(key)
+	Expect(err).NotTo(HaveOccurred())
+	aead, err := cipher.NewGCM(block)
+	Expect(err).NotTo(HaveOccurred())
+	return aead.Seal(nil, nonce, []byte(plaintext), nil)
+}
@@ -0,0 +1,38 @@
+package filething
+
+import "os"
+
+func NewDir(path string) FileThing {
+	return New(path)
+}
+
+func Glob(pattern string, opts ...Option) ([]FileThing, error) {
+	fileThing := New(pattern, opts...)
+	matches, err := fileThing.glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	fileThings := make([]FileThing, 0, len(matches))
+	for _, match := range matches {
+		fileThings = append(fileThings, fileThing.withPath(match))
+	}
+	return fileThings, nil
+}
+
+func (fileThing FileThing) Mkdir(perm os.FileMode) error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("mkdir") {
+		return nil
+	}
+	return fileThing.mkdirAll(fileThing.Path, perm)
+}
+
+func (fileThing FileThing) Walk(fn func(FileThing, os.FileInfo) error) error {
+	return fileThing.walk(fileThing.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return fn(fileThing.withPath(path), info)
+	})
+}
@@ -0,0 +1,354 @@
+package filething
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someDir   string
+	)
+
+	BeforeEach(func() {
+		someDir = createSomeTempDir()
+		fileThing = NewDir(filepath.Join(someDir, "parent", "child"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(someDir)
+		Expect(someDir).NotTo(BeAnExistingFile())
+	})
+
+	Describe("NewDir", func() {
+		It("sets FileThing.Path", func() {
+			Expect(fileThing.Path).To(Equal(filepath.Join(someDir, "parent", "child")))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(fileThing.remove).NotTo(BeNil())
+		})
+	})
+
+	Describe("Glob", func() {
+		var (
+			fileThings []FileThing
+			globErr    error
+			pattern    string
+			glob       Globber
+			opts       []Option
+		)
+
+		BeforeEach(func() {
+			pattern = filepath.Join(someDir, "*.txt")
+			glob = filepath.Glob
+			opts = nil
+			for _, name := range []string{"a.txt", "b.txt", "c.log"} {
+				err := ioutil.WriteFile(filepath.Join(someDir, name), nil, 0644)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		JustBeforeEach(func() {
+			fileThings, globErr = Glob(pattern, append(opts, func(fileThing *FileThing) {
+				fileThing.glob = glob
+			})...)
+		})
+
+		It("does not return an error", func() {
+			Expect(globErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for each match", func() {
+			Expect(fileThings).To(HaveLen(2))
+			Expect(fileThings[0].Path).To(Equal(filepath.Join(someDir, "a.txt")))
+			Expect(fileThings[1].Path).To(Equal(filepath.Join(someDir, "b.txt")))
+		})
+
+		It("returns usable FileThings", func() {
+			Expect(fileThings[0].Remove()).To(Succeed())
+			Expect(filepath.Join(someDir, "a.txt")).NotTo(BeAnExistingFile())
+		})
+
+		Context("when nothing matches", func() {
+			BeforeEach(func() {
+				pattern = filepath.Join(someDir, "*.nothing")
+			})
+
+			It("does not return an error", func() {
+				Expect(globErr).NotTo(HaveOccurred())
+			})
+
+			It("returns an empty slice", func() {
+				Expect(fileThings).NotTo(BeNil())
+				Expect(fileThings).To(BeEmpty())
+			})
+		})
+
+		Context("when the globber is stubbed", func() {
+			BeforeEach(func() {
+				glob = func(string) ([]string, error) {
+					return []string{"some/match", "some/other/match"}, nil
+				}
+			})
+
+			It("sets FileThing.Path to each match", func() {
+				Expect(fileThings).To(HaveLen(2))
+				Expect(fileThings[0].Path).To(Equal("some/match"))
+				Expect(fileThings[1].Path).To(Equal("some/other/match"))
+			})
+
+			It("wires up the default remover", func() {
+				for _, fileThing := range fileThings {
+					Expect(fileThing.remove).NotTo(BeNil())
+				}
+			})
+
+			Context("and options are given", func() {
+				var removedPaths []string
+
+				BeforeEach(func() {
+					removedPaths = nil
+					opts = []Option{WithRemover(func(path string) error {
+						removedPaths = append(removedPaths, path)
+						return nil
+					})}
+				})
+
+				It("applies them to each match", func() {
+					for _, fileThing := range fileThings {
+						Expect(fileThing.Remove()).To(Succeed())
+					}
+					Expect(removedPaths).To(Equal([]string{"some/match", "some/other/match"}))
+				})
+			})
+		})
+
+		Context("when globbing fails", func() {
+			BeforeEach(func() {
+				glob = failToGlob
+			})
+
+			It("returns an error", func() {
+				Expect(globErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(globErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Mkdir", func() {
+		var mkdirErr error
+
+		JustBeforeEach(func() {
+			mkdirErr = fileThing.Mkdir(0755)
+		})
+
+		It("does not return an error", func() {
+			Expect(mkdirErr).NotTo(HaveOccurred())
+		})
+
+		It("creates the directory and its parents", func() {
+			Expect(fileThing.Path).To(BeADirectory())
+		})
+
+		Context("when the directory already exists", func() {
+			BeforeEach(func() {
+				err := os.MkdirAll(fileThing.Path, 0755)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not return an error", func() {
+				Expect(mkdirErr).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the directory maker is stubbed", func() {
+			var mkdirPerm os.FileMode
+
+			BeforeEach(func() {
+				fileThing.mkdirAll = func(path string, perm os.FileMode) error {
+					mkdirPerm = perm
+					return nil
+				}
+			})
+
+			It("passes through the exact permissions", func() {
+				Expect(mkdirPerm).To(Equal(os.FileMode(0755)))
+			})
+		})
+
+		Context("when the parent is read-only", func() {
+			BeforeEach(func() {
+				fileThing.mkdirAll = func(path string, perm os.FileMode) error {
+					return &os.PathError{Op: "mkdir", Path: path, Err: os.ErrPermission}
+				}
+			})
+
+			It("returns a permission error", func() {
+				Expect(errors.Is(mkdirErr, os.ErrPermission)).To(BeTrue())
+			})
+		})
+
+		Context("when creating the directory fails", func() {
+			BeforeEach(func() {
+				fileThing.mkdirAll = failToMkdirAll
+			})
+
+			It("returns an error", func() {
+				Expect(mkdirErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(mkdirErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Walk", func() {
+		var (
+			visited []FileThing
+			fn      func(FileThing, os.FileInfo) error
+			walkErr error
+		)
+
+		BeforeEach(func() {
+			visited = nil
+			fn = func(fileThing FileThing, info os.FileInfo) error {
+				visited = append(visited, fileThing)
+				return nil
+			}
+
+			fileThing = New(someDir)
+			for _, dir := range []string{"a", "b"} {
+				err := os.MkdirAll(filepath.Join(someDir, dir), 0755)
+				Expect(err).NotTo(HaveOccurred())
+				err = ioutil.WriteFile(filepath.Join(someDir, dir, "file"), nil, 0644)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		JustBeforeEach(func() {
+			walkErr = fileThing.Walk(fn)
+		})
+
+		It("does not return an error", func() {
+			Expect(walkErr).NotTo(HaveOccurred())
+		})
+
+		It("visits every entry in lexical order", func() {
+			Expect(paths(visited)).To(Equal([]string{
+				someDir,
+				filepath.Join(someDir, "a"),
+				filepath.Join(someDir, "a", "file"),
+				filepath.Join(someDir, "b"),
+				filepath.Join(someDir, "b", "file"),
+			}))
+		})
+
+		It("visits usable FileThings", func() {
+			Expect(visited[2].Remove()).To(Succeed())
+			Expect(filepath.Join(someDir, "a", "file")).NotTo(BeAnExistingFile())
+		})
+
+		Context("when fn skips a directory", func() {
+			BeforeEach(func() {
+				fn = func(fileThing FileThing, info os.FileInfo) error {
+					visited = append(visited, fileThing)
+					if info.IsDir() && fileThing.Base() == "a" {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+			})
+
+			It("does not descend into it", func() {
+				Expect(paths(visited)).To(Equal([]string{
+					someDir,
+					filepath.Join(someDir, "a"),
+					filepath.Join(someDir, "b"),
+					filepath.Join(someDir, "b", "file"),
+				}))
+			})
+		})
+
+		Context("when the walker is stubbed", func() {
+			BeforeEach(func() {
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					for _, name := range []string{"z", "y", "x"} {
+						if err := walkFn(filepath.Join(root, name), fakeFileInfo{name: name}, nil); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+			})
+
+			It("visits entries in the order the walker reports them", func() {
+				Expect(paths(visited)).To(Equal([]string{
+					filepath.Join(someDir, "z"),
+					filepath.Join(someDir, "y"),
+					filepath.Join(someDir, "x"),
+				}))
+			})
+
+			It("wires up the default remover on each entry", func() {
+				for _, fileThing := range visited {
+					Expect(fileThing.remove).NotTo(BeNil())
+				}
+			})
+		})
+
+		Context("when the walker reports an error", func() {
+			BeforeEach(func() {
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					return walkFn(root, nil, errors.New("I failed"))
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(walkErr).To(MatchError("I failed"))
+			})
+
+			It("does not call fn", func() {
+				Expect(visited).To(BeEmpty())
+			})
+		})
+
+		Context("when fn fails", func() {
+			BeforeEach(func() {
+				fn = func(FileThing, os.FileInfo) error {
+					return errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(walkErr).To(MatchError("I failed"))
+			})
+		})
+	})
+})
+
+func paths(fileThings []FileThing) []string {
+	var paths []string
+	for _, fileThing := range fileThings {
+		paths = append(paths, fileThing.Path)
+	}
+	return paths
+}
+
+func failToGlob(pattern string) ([]string, error) {
+	return nil, errors.New("I failed")
+}
+
+func failToMkdirAll(path string, perm os.FileMode) error {
+	return errors.New("I failed")
+}
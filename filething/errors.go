@@ -0,0 +1,15 @@
+package filething
+
+type PathError struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (err *PathError) Error() string {
+	return err.Op + " " + err.Path + ": " + err.Err.Error()
+}
+
+func (err *PathError) Unwrap() error {
+	return err.Err
+}
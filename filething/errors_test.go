@@ -0,0 +1,65 @@
+package filething
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PathError", func() {
+	var pathErr *PathError
+
+	BeforeEach(func() {
+		pathErr = &PathError{Op: "read", Path: "some/path", Err: os.ErrNotExist}
+	})
+
+	It("reports the operation, path and underlying error", func() {
+		Expect(pathErr).To(MatchError("read some/path: file does not exist"))
+	})
+
+	It("unwraps to the underlying error", func() {
+		Expect(errors.Is(pathErr, os.ErrNotExist)).To(BeTrue())
+	})
+
+	Context("when a read fails", func() {
+		var (
+			fileThing FileThing
+			readErr   error
+		)
+
+		BeforeEach(func() {
+			fileThing = New("some/path")
+			fileThing.read = failToRead
+		})
+
+		JustBeforeEach(func() {
+			_, readErr = fileThing.Read()
+		})
+
+		It("can be extracted with errors.As", func() {
+			Expect(errors.As(readErr, &pathErr)).To(BeTrue())
+		})
+
+		It("records the operation and path", func() {
+			Expect(errors.As(readErr, &pathErr)).To(BeTrue())
+			Expect(pathErr.Op).To(Equal("read"))
+			Expect(pathErr.Path).To(Equal("some/path"))
+		})
+
+		It("wraps the reader's error", func() {
+			Expect(errors.As(readErr, &pathErr)).To(BeTrue())
+			Expect(pathErr.Err).To(MatchError("I failed"))
+		})
+	})
+
+	Context("when FileThing.Path doesn't exist", func() {
+		It("still matches os.ErrNotExist", func() {
+			_, err := New("some/missing/path").Size()
+			Expect(errors.As(err, &pathErr)).To(BeTrue())
+			Expect(pathErr.Op).To(Equal("size"))
+			Expect(errors.Is(err, os.ErrNotExist)).To(BeTrue())
+		})
+	})
+})
@@ -1,25 +1,259 @@
 package filething
 
-import "os"
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
 
 type Remover func(string) error
 
+type Copier func(src, dst string) error
+
+type Renamer func(oldpath, newpath string) error
+
+type Reader func(string) ([]byte, error)
+
+type Writer func(string, []byte, os.FileMode) error
+
+type FileOpener func(string, int, os.FileMode) (io.WriteCloser, error)
+
+type Stater func(string) (os.FileInfo, error)
+
+type Opener func(string) (io.ReadCloser, error)
+
+type Creator func(string) (io.WriteCloser, error)
+
+type Chmoder func(string, os.FileMode) error
+
+type TimesChanger func(string, time.Time, time.Time) error
+
+type Truncator func(string, int64) error
+
+type Linker func(oldname, newname string) error
+
+type LinkReader func(string) (string, error)
+
+type TempCreator func(dir, pattern string) (*os.File, error)
+
+type DirMaker func(string, os.FileMode) error
+
+type FileLocker func(*os.File) error
+
+type SeekOpener func(string) (io.ReadSeekCloser, error)
+
+type Walker func(string, filepath.WalkFunc) error
+
+type FSStater func(string) (availableBlocks, blockSize uint64, err error)
+
+type Timer func(time.Duration) <-chan time.Time
+
+type Syncer func(io.WriteCloser) error
+
+type Sleeper func(time.Duration)
+
+type Logf func(format string, args ...interface{})
+
+type Chowner func(string, int, int) error
+
+type PathResolver func(string) (string, error)
+
+type RawOpener func(string, int, os.FileMode) (*os.File, error)
+
+type Clock func() time.Time
+
+type DirReader func(string) ([]os.DirEntry, error)
+
+type HomeDirFinder func() (string, error)
+
+type EnvLookup func(string) (string, bool)
+
+type HTTPGetter func(string) (*http.Response, error)
+
+type Globber func(string) ([]string, error)
+
 type FileThing struct {
-	Path   string
-	remove Remover
+	Path        string
+	remove      Remover
+	copy        Copier
+	rename      Renamer
+	read        Reader
+	write       Writer
+	openFile    FileOpener
+	stat        Stater
+	open        Opener
+	chmod       Chmoder
+	chtimes     TimesChanger
+	truncate    Truncator
+	symlink     Linker
+	readlink    LinkReader
+	createTemp  TempCreator
+	removeAll   Remover
+	mkdirAll    DirMaker
+	newWatcher  WatcherCreator
+	lockFile    FileLocker
+	unlockFile  FileLocker
+	create      Creator
+	openSeeker  SeekOpener
+	walk        Walker
+	statfs      FSStater
+	after       Timer
+	sync        Syncer
+	sleep       Sleeper
+	dryRunLogf  Logf
+	link        Linker
+	chown       Chowner
+	statCache   *statCache
+	hooks       Hooks
+	random      io.Reader
+	abs         PathResolver
+	removeOnce  *removeOnce
+	openRaw     RawOpener
+	existCache  *existsCache
+	now         Clock
+	readDir     DirReader
+	userHomeDir HomeDirFinder
+	lookupEnv   EnvLookup
+	httpGet     HTTPGetter
+	glob        Globber
 }
 
-func New(path string) FileThing {
-	return FileThing{
-		Path:   path,
-		remove: os.Remove,
+func New(path string, opts ...Option) FileThing {
+	fileThing := FileThing{
+		Path:        path,
+		remove:      os.Remove,
+		copy:        copyFile,
+		rename:      os.Rename,
+		read:        ioutil.ReadFile,
+		write:       ioutil.WriteFile,
+		openFile:    openFile,
+		stat:        os.Stat,
+		open:        open,
+		chmod:       os.Chmod,
+		chtimes:     os.Chtimes,
+		truncate:    os.Truncate,
+		symlink:     os.Symlink,
+		readlink:    os.Readlink,
+		createTemp:  ioutil.TempFile,
+		removeAll:   os.RemoveAll,
+		mkdirAll:    os.MkdirAll,
+		newWatcher:  newPollWatcher,
+		lockFile:    lockFile,
+		unlockFile:  unlockFile,
+		create:      create,
+		openSeeker:  openSeeker,
+		walk:        filepath.Walk,
+		statfs:      statfs,
+		after:       time.After,
+		sync:        syncFile,
+		sleep:       time.Sleep,
+		link:        os.Link,
+		chown:       os.Chown,
+		random:      rand.Reader,
+		abs:         filepath.Abs,
+		removeOnce:  new(removeOnce),
+		openRaw:     os.OpenFile,
+		now:         time.Now,
+		readDir:     os.ReadDir,
+		userHomeDir: os.UserHomeDir,
+		lookupEnv:   os.LookupEnv,
+		httpGet:     http.Get,
+		glob:        filepath.Glob,
 	}
+
+	for _, opt := range opts {
+		opt(&fileThing)
+	}
+	return fileThing
 }
 
 func (fileThing FileThing) Remove() error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("remove") {
+		return nil
+	}
+	if fileThing.hooks.BeforeRemove != nil {
+		fileThing.hooks.BeforeRemove(fileThing.Path)
+	}
+
 	err := fileThing.remove(fileThing.Path)
 	if os.IsNotExist(err) {
-		return nil
+		err = nil
+	}
+
+	if fileThing.hooks.AfterRemove != nil {
+		fileThing.hooks.AfterRemove(fileThing.Path, err)
 	}
 	return err
 }
+
+// Open returns the raw handle; the caller is responsible for closing it.
+func (fileThing FileThing) Open(flag int, perm os.FileMode) (*os.File, error) {
+	defer fileThing.invalidateExists()
+	return fileThing.openRaw(fileThing.Path, flag, perm)
+}
+
+func (fileThing FileThing) dryRun(op string) bool {
+	if fileThing.dryRunLogf == nil {
+		return false
+	}
+	fileThing.dryRunLogf("dry run: %s %s", op, fileThing.Path)
+	return true
+}
+
+func (fileThing FileThing) withPath(path string) FileThing {
+	fileThing.Path = path
+	fileThing.removeOnce = new(removeOnce)
+	if fileThing.statCache != nil {
+		fileThing = fileThing.WithCachedStat()
+	}
+	if fileThing.existCache != nil {
+		fileThing.existCache = new(existsCache)
+	}
+	return fileThing
+}
+
+func open(name string) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func openSeeker(name string) (io.ReadSeekCloser, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func create(name string) (io.WriteCloser, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func openFile(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+	file, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func syncFile(file io.WriteCloser) error {
+	syncer, ok := file.(interface{ Sync() error })
+	if !ok {
+		return errors.New("file does not support sync")
+	}
+	return syncer.Sync()
+}
@@ -65,6 +65,67 @@ var _ = Describe("FileThing", func() {
 			})
 		})
 	})
+
+	Describe("#Open", func() {
+		var (
+			file    *os.File
+			openErr error
+		)
+
+		JustBeforeEach(func() {
+			file, openErr = fileThing.Open(os.O_RDWR, 0600)
+		})
+
+		AfterEach(func() {
+			if file != nil {
+				file.Close()
+			}
+		})
+
+		It("does not return an error", func() {
+			Expect(openErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a handle to FileThing.Path", func() {
+			Expect(file.Name()).To(Equal(someFile))
+			_, err := file.WriteString("some contents")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+		})
+
+		Context("when the opener is stubbed", func() {
+			var (
+				openedPath string
+				openedFlag int
+				openedPerm os.FileMode
+			)
+
+			BeforeEach(func() {
+				fileThing.openRaw = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+					openedPath, openedFlag, openedPerm = name, flag, perm
+					return nil, nil
+				}
+			})
+
+			It("forwards the path, flag and perm", func() {
+				Expect(openedPath).To(Equal(someFile))
+				Expect(openedFlag).To(Equal(os.O_RDWR))
+				Expect(openedPerm).To(Equal(os.FileMode(0600)))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openRaw = func(string, int, os.FileMode) (*os.File, error) {
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(openErr).To(MatchError("I failed"))
+			})
+		})
+	})
 })
 
 func createSomeTempFile() string {
@@ -77,3 +138,20 @@ func createSomeTempFile() string {
 func failToRemove(path string) error {
 	return errors.New("I failed")
 }
+
+func createSomeTempDir() string {
+	tempDir, err := ioutil.TempDir("", "")
+	Expect(err).NotTo(HaveOccurred())
+	return tempDir
+}
+
+func listDir(dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	Expect(err).NotTo(HaveOccurred())
+
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
@@ -0,0 +1,87 @@
+package filething
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+func (fileThing FileThing) Compress(destPath string) (FileThing, error) {
+	if fileThing.dryRun("compress") {
+		return fileThing.withPath(destPath), nil
+	}
+	source, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return FileThing{}, err
+	}
+	defer source.Close()
+
+	destination, err := fileThing.create(destPath)
+	if err != nil {
+		return FileThing{}, err
+	}
+	compressed := fileThing.withPath(destPath)
+
+	if err := compress(destination, source); err != nil {
+		destination.Close()
+		compressed.Remove()
+		return FileThing{}, &PathError{Op: "compress", Path: fileThing.Path, Err: err}
+	}
+	if err := destination.Close(); err != nil {
+		compressed.Remove()
+		return FileThing{}, &PathError{Op: "compress", Path: fileThing.Path, Err: err}
+	}
+	return compressed, nil
+}
+
+func compress(dst io.Writer, src io.Reader) error {
+	writer := gzip.NewWriter(dst)
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (fileThing FileThing) Decompress(destPath string) (FileThing, error) {
+	if fileThing.dryRun("decompress") {
+		return fileThing.withPath(destPath), nil
+	}
+	source, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return FileThing{}, err
+	}
+	defer source.Close()
+
+	reader, err := gzip.NewReader(source)
+	if err != nil {
+		return FileThing{}, fileThing.decompressError(err)
+	}
+	defer reader.Close()
+
+	destination, err := fileThing.create(destPath)
+	if err != nil {
+		return FileThing{}, err
+	}
+	decompressed := fileThing.withPath(destPath)
+
+	if _, err := io.Copy(destination, reader); err != nil {
+		destination.Close()
+		decompressed.Remove()
+		return FileThing{}, fileThing.decompressError(err)
+	}
+	if err := destination.Close(); err != nil {
+		decompressed.Remove()
+		return FileThing{}, fileThing.decompressError(err)
+	}
+	return decompressed, nil
+}
+
+func (fileThing FileThing) decompressError(err error) error {
+	if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &PathError{Op: "decompress", Path: fileThing.Path, Err: fmt.Errorf("invalid gzip data: %w", err)}
+	}
+	return &PathError{Op: "decompress", Path: fileThing.Path, Err: err}
+}
@@ -0,0 +1,301 @@
+package filething
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+		someDest  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		someDest = someFile + ".gz"
+		fileThing = New(someFile)
+
+		err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		os.Remove(someDest)
+		Expect(someFile).NotTo(BeAnExistingFile())
+		Expect(someDest).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Compress", func() {
+		var (
+			compressed  FileThing
+			compressErr error
+		)
+
+		JustBeforeEach(func() {
+			compressed, compressErr = fileThing.Compress(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(compressErr).NotTo(HaveOccurred())
+		})
+
+		It("gzip-compresses the file", func() {
+			Expect(gunzipFile(someDest)).To(Equal([]byte("some contents")))
+		})
+
+		It("returns a FileThing for the compressed file", func() {
+			Expect(compressed.Path).To(Equal(someDest))
+		})
+
+		Context("when the opener and creator are stubbed", func() {
+			var destination *fakeWriteCloser
+
+			BeforeEach(func() {
+				destination = new(fakeWriteCloser)
+				fileThing.open = openString("stubbed contents")
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return destination, nil
+				}
+			})
+
+			It("compresses the stubbed contents", func() {
+				Expect(gunzip(destination.Bytes())).To(Equal([]byte("stubbed contents")))
+			})
+
+			It("closes the destination", func() {
+				Expect(destination.closed).To(BeTrue())
+			})
+
+			Context("and closing the destination fails", func() {
+				BeforeEach(func() {
+					destination.closeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(compressErr).To(MatchError("compress " + someFile + ": I failed"))
+				})
+			})
+		})
+
+		Context("when reading FileThing.Path fails part way", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(io.MultiReader(
+						strings.NewReader("some"),
+						iotest.ErrReader(errors.New("I failed")),
+					)), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(compressErr).To(MatchError("compress " + someFile + ": I failed"))
+			})
+
+			It("removes the partial destination", func() {
+				Expect(someDest).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when closing the gzip writer fails", func() {
+			BeforeEach(func() {
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return &shortWriteCloser{limit: 10}, nil
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(compressErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(compressErr).To(MatchError("compress " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(compressErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when creating the destination fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(compressErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Decompress", func() {
+		var (
+			someOutput    string
+			decompressed  FileThing
+			decompressErr error
+		)
+
+		BeforeEach(func() {
+			someOutput = someFile + ".out"
+			_, err := fileThing.Compress(someDest)
+			Expect(err).NotTo(HaveOccurred())
+			fileThing = New(someDest)
+		})
+
+		AfterEach(func() {
+			os.Remove(someOutput)
+		})
+
+		JustBeforeEach(func() {
+			decompressed, decompressErr = fileThing.Decompress(someOutput)
+		})
+
+		It("does not return an error", func() {
+			Expect(decompressErr).NotTo(HaveOccurred())
+		})
+
+		It("round-trips what Compress produced", func() {
+			Expect(ioutil.ReadFile(someOutput)).To(Equal([]byte("some contents")))
+		})
+
+		It("returns a FileThing for the decompressed file", func() {
+			Expect(decompressed.Path).To(Equal(someOutput))
+		})
+
+		Context("when the opener is stubbed with a valid gzip stream", func() {
+			BeforeEach(func() {
+				fileThing.open = openString(string(gzipString("stubbed contents")))
+			})
+
+			It("decompresses the stubbed stream", func() {
+				Expect(ioutil.ReadFile(someOutput)).To(Equal([]byte("stubbed contents")))
+			})
+		})
+
+		Context("when FileThing.Path is not gzip data", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("not gzip at all")
+			})
+
+			It("reports invalid gzip data", func() {
+				Expect(errors.Is(decompressErr, gzip.ErrHeader)).To(BeTrue())
+				Expect(decompressErr).To(MatchError(ContainSubstring("decompress " + someDest + ": invalid gzip data")))
+			})
+
+			It("does not create the destination", func() {
+				Expect(someOutput).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the gzip stream is truncated", func() {
+			BeforeEach(func() {
+				data := gzipString(strings.Repeat("some contents", 1000))
+				fileThing.open = openString(string(data[:len(data)-10]))
+			})
+
+			It("reports invalid gzip data", func() {
+				Expect(decompressErr).To(MatchError(ContainSubstring("decompress " + someDest + ": invalid gzip data")))
+			})
+
+			It("removes the partial destination", func() {
+				Expect(someOutput).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when writing the destination fails", func() {
+			BeforeEach(func() {
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return &fakeWriteCloser{writeErr: errors.New("I failed")}, nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(decompressErr).To(MatchError("decompress " + someDest + ": I failed"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(decompressErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when creating the destination fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(decompressErr).To(MatchError("I failed"))
+			})
+		})
+	})
+})
+
+type shortWriteCloser struct {
+	buffer bytes.Buffer
+	limit  int
+}
+
+func (file *shortWriteCloser) Write(data []byte) (int, error) {
+	if file.buffer.Len()+len(data) <= file.limit {
+		return file.buffer.Write(data)
+	}
+	n, _ := file.buffer.Write(data[:file.limit-file.buffer.Len()])
+	return n, errors.New("I failed")
+}
+
+func (file *shortWriteCloser) Close() error {
+	return nil
+}
+
+func failToCreate(name string) (io.WriteCloser, error) {
+	return nil, errors.New("I failed")
+}
+
+func gzipString(contents string) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(contents))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(writer.Close()).To(Succeed())
+	return buffer.Bytes()
+}
+
+func gunzipFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return gunzip(data)
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
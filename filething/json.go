@@ -0,0 +1,44 @@
+package filething
+
+import "encoding/json"
+
+func (fileThing FileThing) ReadJSON(v interface{}) error {
+	data, err := fileThing.Read()
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return &PathError{Op: "readjson", Path: fileThing.Path, Err: err}
+	}
+	return nil
+}
+
+func (fileThing FileThing) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return fileThing.Write(data)
+}
+
+func (fileThing FileThing) WriteJSONIndented(v interface{}, indent string) error {
+	data, err := json.MarshalIndent(v, "", indent)
+	if err != nil {
+		return err
+	}
+	return fileThing.WriteAtomic(data)
+}
+
+func (fileThing FileThing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fileThing.Path)
+}
+
+func (fileThing *FileThing) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err != nil {
+		return err
+	}
+	*fileThing = New(path)
+	return nil
+}
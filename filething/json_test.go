@@ -0,0 +1,271 @@
+package filething
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type someConfig struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#WriteJSON and #ReadJSON", func() {
+		It("round trips a value", func() {
+			Expect(fileThing.WriteJSON(someConfig{Name: "some name", Count: 3})).To(Succeed())
+
+			var config someConfig
+			Expect(fileThing.ReadJSON(&config)).To(Succeed())
+			Expect(config).To(Equal(someConfig{Name: "some name", Count: 3}))
+		})
+	})
+
+	Describe("#ReadJSON", func() {
+		var (
+			config  someConfig
+			readErr error
+		)
+
+		BeforeEach(func() {
+			config = someConfig{}
+			fileThing.read = func(string) ([]byte, error) {
+				return []byte(`{"name": "some name", "count": 3}`), nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			readErr = fileThing.ReadJSON(&config)
+		})
+
+		It("does not return an error", func() {
+			Expect(readErr).NotTo(HaveOccurred())
+		})
+
+		It("decodes the file contents", func() {
+			Expect(config).To(Equal(someConfig{Name: "some name", Count: 3}))
+		})
+
+		Context("when FileThing.Path contains malformed JSON", func() {
+			BeforeEach(func() {
+				fileThing.read = func(string) ([]byte, error) {
+					return []byte(`{"name": `), nil
+				}
+			})
+
+			It("returns a JSON syntax error", func() {
+				var syntaxErr *json.SyntaxError
+				Expect(errors.As(readErr, &syntaxErr)).To(BeTrue())
+			})
+
+			It("reports the path", func() {
+				Expect(readErr).To(MatchError(ContainSubstring("readjson " + someFile + ": ")))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.read = failToRead
+			})
+
+			It("reports the correct error", func() {
+				Expect(readErr).To(MatchError("read " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#WriteJSON", func() {
+		var (
+			written  []byte
+			writeErr error
+			value    interface{}
+		)
+
+		BeforeEach(func() {
+			written = nil
+			value = someConfig{Name: "some name", Count: 3}
+			fileThing.write = func(path string, data []byte, mode os.FileMode) error {
+				written = data
+				return nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			writeErr = fileThing.WriteJSON(value)
+		})
+
+		It("does not return an error", func() {
+			Expect(writeErr).NotTo(HaveOccurred())
+		})
+
+		It("writes the encoded value", func() {
+			Expect(written).To(MatchJSON(`{"name": "some name", "count": 3}`))
+		})
+
+		Context("when the value cannot be encoded", func() {
+			BeforeEach(func() {
+				value = make(chan int)
+			})
+
+			It("returns an error", func() {
+				Expect(writeErr).To(HaveOccurred())
+			})
+
+			It("writes nothing", func() {
+				Expect(written).To(BeNil())
+			})
+		})
+
+		Context("when writing FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.write = failToWrite
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#WriteJSONIndented", func() {
+		var (
+			someDir  string
+			writeErr error
+			value    interface{}
+		)
+
+		BeforeEach(func() {
+			someDir = createSomeTempDir()
+			fileThing = New(filepath.Join(someDir, "config.json"))
+			value = someConfig{Name: "some name", Count: 3}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		JustBeforeEach(func() {
+			writeErr = fileThing.WriteJSONIndented(value, "\t")
+		})
+
+		It("does not return an error", func() {
+			Expect(writeErr).NotTo(HaveOccurred())
+		})
+
+		It("writes the value indented with the given string", func() {
+			Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("{\n\t\"name\": \"some name\",\n\t\"count\": 3\n}")))
+		})
+
+		It("leaves no temp file behind", func() {
+			Expect(listDir(someDir)).To(ConsistOf("config.json"))
+		})
+
+		Context("when the value cannot be encoded", func() {
+			BeforeEach(func() {
+				value = make(chan int)
+			})
+
+			It("returns the encoding error", func() {
+				var unsupported *json.UnsupportedTypeError
+				Expect(errors.As(writeErr, &unsupported)).To(BeTrue())
+			})
+
+			It("does not create the file", func() {
+				Expect(listDir(someDir)).To(BeEmpty())
+			})
+		})
+
+		Context("when writing fails", func() {
+			BeforeEach(func() {
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeErr).To(MatchError("I failed"))
+			})
+
+			It("does not create the file", func() {
+				Expect(listDir(someDir)).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#MarshalJSON", func() {
+		It("serializes the path as a JSON string", func() {
+			data, err := json.Marshal(fileThing)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`"` + someFile + `"`))
+		})
+
+		It("serializes when embedded in a struct", func() {
+			data, err := json.Marshal(struct {
+				File FileThing `json:"file"`
+			}{File: fileThing})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"file": "` + someFile + `"}`))
+		})
+	})
+
+	Describe("#UnmarshalJSON", func() {
+		var (
+			decoded   FileThing
+			decodeErr error
+			data      []byte
+		)
+
+		BeforeEach(func() {
+			var err error
+			data, err = json.Marshal(fileThing)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			decoded = FileThing{}
+			decodeErr = json.Unmarshal(data, &decoded)
+		})
+
+		It("does not return an error", func() {
+			Expect(decodeErr).NotTo(HaveOccurred())
+		})
+
+		It("restores the path", func() {
+			Expect(decoded.Path).To(Equal(someFile))
+		})
+
+		It("wires up the default behaviour", func() {
+			Expect(decoded.Remove()).To(Succeed())
+			Expect(someFile).NotTo(BeAnExistingFile())
+		})
+
+		Context("when the data is not a JSON string", func() {
+			BeforeEach(func() {
+				data = []byte(`{"path": "nope"}`)
+			})
+
+			It("returns an error", func() {
+				Expect(decodeErr).To(HaveOccurred())
+			})
+		})
+	})
+})
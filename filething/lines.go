@@ -0,0 +1,252 @@
+package filething
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var tailBlockSize int64 = 4096
+
+type Match struct {
+	Line int
+	Text string
+}
+
+func (fileThing FileThing) ForEachLine(fn func(line string) error) error {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := fn(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (fileThing FileThing) Tail(n int) ([]string, error) {
+	if n <= 0 {
+		return []string{}, nil
+	}
+
+	file, err := fileThing.openSeeker(fileThing.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for offset > 0 && bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) < n {
+		size := tailBlockSize
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		block := make([]byte, size)
+		if _, err := io.ReadFull(file, block); err != nil {
+			return nil, err
+		}
+		data = append(block, data...)
+	}
+
+	if len(data) == 0 {
+		return []string{}, nil
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+func (fileThing FileThing) CountLines() (int, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var (
+		count  int
+		last   byte = '\n'
+		buffer      = make([]byte, 32*1024)
+	)
+	for {
+		n, err := file.Read(buffer)
+		if n > 0 {
+			count += bytes.Count(buffer[:n], []byte("\n"))
+			last = buffer[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if last != '\n' {
+		count++
+	}
+	return count, nil
+}
+
+func (fileThing FileThing) Grep(pattern string) ([]Match, error) {
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	matches := []Match{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if expression.MatchString(scanner.Text()) {
+			matches = append(matches, Match{Line: line, Text: scanner.Text()})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+func (fileThing FileThing) ReadLines() ([]string, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (fileThing FileThing) WriteLines(lines []string) error {
+	if len(lines) == 0 {
+		return fileThing.WriteAtomic([]byte{})
+	}
+	return fileThing.WriteAtomic([]byte(strings.Join(lines, "\n") + "\n"))
+}
+
+func (fileThing FileThing) InsertLineAt(lineNum int, text string) error {
+	if lineNum <= 0 {
+		return &PathError{Op: "insert", Path: fileThing.Path, Err: fmt.Errorf("invalid line number %d", lineNum)}
+	}
+
+	data, err := fileThing.Read()
+	if err != nil {
+		return err
+	}
+
+	lines := splitLines(data)
+	if lineNum > len(lines) {
+		lineNum = len(lines) + 1
+	}
+	lines = append(lines[:lineNum-1], append([]string{text}, lines[lineNum-1:]...)...)
+	return fileThing.WriteAtomic(joinLines(lines, data))
+}
+
+func (fileThing FileThing) DeleteLineAt(lineNum int) error {
+	data, err := fileThing.Read()
+	if err != nil {
+		return err
+	}
+
+	lines := splitLines(data)
+	if lineNum <= 0 || lineNum > len(lines) {
+		return &PathError{Op: "delete", Path: fileThing.Path, Err: fmt.Errorf("line %d out of range (file has %d lines)", lineNum, len(lines))}
+	}
+	return fileThing.WriteAtomic(joinLines(append(lines[:lineNum-1], lines[lineNum:]...), data))
+}
+
+// FilterLines leaves FileThing.Path untouched when every line is kept.
+func (fileThing FileThing) FilterLines(keep func(line string) bool) (int, error) {
+	kept, removed, err := fileThing.keptLines(keep)
+	if err != nil || removed == 0 {
+		return 0, err
+	}
+	if err := fileThing.WriteAtomic(kept); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+func (fileThing FileThing) keptLines(keep func(line string) bool) ([]byte, int, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var (
+		kept    bytes.Buffer
+		removed int
+		reader  = bufio.NewReader(file)
+	)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if keep(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")) {
+				kept.WriteString(line)
+			} else {
+				removed++
+			}
+		}
+		if err == io.EOF {
+			return kept.Bytes(), removed, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// joinLines keeps the original file's choice of trailing newline, treating an
+// empty original as wanting one.
+func joinLines(lines []string, original []byte) []byte {
+	if len(lines) == 0 {
+		return []byte{}
+	}
+	joined := strings.Join(lines, "\n")
+	if len(original) == 0 || bytes.HasSuffix(original, []byte("\n")) {
+		joined += "\n"
+	}
+	return []byte(joined)
+}
@@ -0,0 +1,895 @@
+package filething
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#ForEachLine", func() {
+		var (
+			lines          []string
+			fn             func(string) error
+			forEachLineErr error
+		)
+
+		BeforeEach(func() {
+			lines = nil
+			fn = func(line string) error {
+				lines = append(lines, line)
+				return nil
+			}
+
+			err := ioutil.WriteFile(someFile, []byte("one\ntwo\nthree\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			forEachLineErr = fileThing.ForEachLine(fn)
+		})
+
+		It("does not return an error", func() {
+			Expect(forEachLineErr).NotTo(HaveOccurred())
+		})
+
+		It("calls fn for each line", func() {
+			Expect(lines).To(Equal([]string{"one", "two", "three"}))
+		})
+
+		Context("when the last line has no trailing newline", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("one\ntwo")
+			})
+
+			It("still delivers the last line", func() {
+				Expect(lines).To(Equal([]string{"one", "two"}))
+			})
+		})
+
+		Context("when fn fails", func() {
+			BeforeEach(func() {
+				fn = func(line string) error {
+					lines = append(lines, line)
+					if line == "two" {
+						return errors.New("I failed")
+					}
+					return nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(forEachLineErr).To(MatchError("I failed"))
+			})
+
+			It("stops early", func() {
+				Expect(lines).To(Equal([]string{"one", "two"}))
+			})
+		})
+
+		Context("when a line is too long to scan", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("one\n" + strings.Repeat("a", bufio.MaxScanTokenSize+1) + "\nthree\n")
+			})
+
+			It("surfaces the scanner error", func() {
+				Expect(forEachLineErr).To(MatchError(bufio.ErrTooLong))
+			})
+
+			It("does not silently deliver a truncated line", func() {
+				Expect(lines).To(Equal([]string{"one"}))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(forEachLineErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Tail", func() {
+		var (
+			n       int
+			lines   []string
+			tailErr error
+		)
+
+		BeforeEach(func() {
+			n = 2
+			err := ioutil.WriteFile(someFile, []byte("one\ntwo\nthree\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			lines, tailErr = fileThing.Tail(n)
+		})
+
+		It("does not return an error", func() {
+			Expect(tailErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the last n lines", func() {
+			Expect(lines).To(Equal([]string{"two", "three"}))
+		})
+
+		Context("when the file has exactly n lines", func() {
+			BeforeEach(func() {
+				n = 3
+			})
+
+			It("returns every line", func() {
+				Expect(lines).To(Equal([]string{"one", "two", "three"}))
+			})
+		})
+
+		Context("when the file has fewer than n lines", func() {
+			BeforeEach(func() {
+				n = 10
+			})
+
+			It("returns every line", func() {
+				Expect(lines).To(Equal([]string{"one", "two", "three"}))
+			})
+		})
+
+		Context("when n is not positive", func() {
+			BeforeEach(func() {
+				n = 0
+			})
+
+			It("does not return an error", func() {
+				Expect(tailErr).NotTo(HaveOccurred())
+			})
+
+			It("returns no lines", func() {
+				Expect(lines).NotTo(BeNil())
+				Expect(lines).To(BeEmpty())
+			})
+		})
+
+		Context("when the file is empty", func() {
+			BeforeEach(func() {
+				fileThing.openSeeker = openSeekableString("")
+			})
+
+			It("returns no lines", func() {
+				Expect(lines).To(BeEmpty())
+			})
+		})
+
+		Context("when the file spans several blocks", func() {
+			var originalBlockSize int64
+
+			BeforeEach(func() {
+				originalBlockSize = tailBlockSize
+				tailBlockSize = 4
+				fileThing.openSeeker = openSeekableString("first line\nsecond line\nthird line\nfourth line")
+			})
+
+			AfterEach(func() {
+				tailBlockSize = originalBlockSize
+			})
+
+			It("returns the last n lines", func() {
+				Expect(lines).To(Equal([]string{"third line", "fourth line"}))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openSeeker = failToOpenSeeker
+			})
+
+			It("reports the correct error", func() {
+				Expect(tailErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#CountLines", func() {
+		var (
+			count    int
+			countErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("one\ntwo\nthree\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			count, countErr = fileThing.CountLines()
+		})
+
+		It("does not return an error", func() {
+			Expect(countErr).NotTo(HaveOccurred())
+		})
+
+		It("counts the lines", func() {
+			Expect(count).To(Equal(3))
+		})
+
+		Context("when FileThing.Path is empty", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("")
+			})
+
+			It("returns zero", func() {
+				Expect(count).To(BeZero())
+			})
+		})
+
+		Context("when FileThing.Path is a single line without a trailing newline", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("one")
+			})
+
+			It("counts the final line", func() {
+				Expect(count).To(Equal(1))
+			})
+		})
+
+		Context("when the last of several lines has no trailing newline", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("one\ntwo\nthree")
+			})
+
+			It("counts the final line", func() {
+				Expect(count).To(Equal(3))
+			})
+		})
+
+		Context("when FileThing.Path contains blank lines", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("\n\n\n")
+			})
+
+			It("counts them", func() {
+				Expect(count).To(Equal(3))
+			})
+		})
+
+		Context("when FileThing.Path is larger than a single chunk", func() {
+			BeforeEach(func() {
+				fileThing.open = openString(strings.Repeat(strings.Repeat("x", 99)+"\n", 1000))
+			})
+
+			It("counts lines across chunks", func() {
+				Expect(count).To(Equal(1000))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(io.MultiReader(strings.NewReader("one\n"), failingReader{})), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(countErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(countErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Grep", func() {
+		var (
+			pattern string
+			matches []Match
+			grepErr error
+		)
+
+		BeforeEach(func() {
+			pattern = "^t"
+			fileThing.open = openString("one\ntwo\nthree\nfour")
+		})
+
+		JustBeforeEach(func() {
+			matches, grepErr = fileThing.Grep(pattern)
+		})
+
+		It("does not return an error", func() {
+			Expect(grepErr).NotTo(HaveOccurred())
+		})
+
+		It("returns each matching line with its 1-based line number", func() {
+			Expect(matches).To(Equal([]Match{
+				{Line: 2, Text: "two"},
+				{Line: 3, Text: "three"},
+			}))
+		})
+
+		Context("when nothing matches", func() {
+			BeforeEach(func() {
+				pattern = "five"
+			})
+
+			It("does not return an error", func() {
+				Expect(grepErr).NotTo(HaveOccurred())
+			})
+
+			It("returns no matches", func() {
+				Expect(matches).To(BeEmpty())
+				Expect(matches).NotTo(BeNil())
+			})
+		})
+
+		Context("when the pattern is invalid", func() {
+			var openCalled bool
+
+			BeforeEach(func() {
+				pattern = "("
+				openCalled = false
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					openCalled = true
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports the invalid pattern", func() {
+				Expect(grepErr).To(MatchError(ContainSubstring(`invalid pattern "("`)))
+			})
+
+			It("does not open FileThing.Path", func() {
+				Expect(openCalled).To(BeFalse())
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(io.MultiReader(strings.NewReader("two\n"), failingReader{})), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(grepErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(grepErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#ReadLines", func() {
+		var (
+			lines   []string
+			readErr error
+		)
+
+		BeforeEach(func() {
+			fileThing.open = openString("one\ntwo\nthree\n")
+		})
+
+		JustBeforeEach(func() {
+			lines, readErr = fileThing.ReadLines()
+		})
+
+		It("does not return an error", func() {
+			Expect(readErr).NotTo(HaveOccurred())
+		})
+
+		It("returns each line without its terminator", func() {
+			Expect(lines).To(Equal([]string{"one", "two", "three"}))
+		})
+
+		Context("when the file has Windows line endings", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("one\r\ntwo\r\nthree\r\n")
+			})
+
+			It("strips the carriage returns", func() {
+				Expect(lines).To(Equal([]string{"one", "two", "three"}))
+			})
+		})
+
+		Context("when the file has no trailing newline", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("one\ntwo\nthree")
+			})
+
+			It("includes the final line", func() {
+				Expect(lines).To(Equal([]string{"one", "two", "three"}))
+			})
+		})
+
+		Context("when the file is empty", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("")
+			})
+
+			It("returns no lines", func() {
+				Expect(lines).To(BeEmpty())
+				Expect(lines).NotTo(BeNil())
+			})
+		})
+
+		Context("when reading the real file", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile)
+				Expect(ioutil.WriteFile(someFile, []byte("one\ntwo\n"), 0644)).To(Succeed())
+			})
+
+			It("returns its lines", func() {
+				Expect(lines).To(Equal([]string{"one", "two"}))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(readErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#WriteLines", func() {
+		var (
+			lines    []string
+			writeErr error
+		)
+
+		BeforeEach(func() {
+			lines = []string{"one", "two", "three"}
+		})
+
+		JustBeforeEach(func() {
+			writeErr = fileThing.WriteLines(lines)
+		})
+
+		It("does not return an error", func() {
+			Expect(writeErr).NotTo(HaveOccurred())
+		})
+
+		It("writes each line followed by a newline", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\n")))
+		})
+
+		Context("when there are no lines", func() {
+			BeforeEach(func() {
+				lines = []string{}
+				Expect(ioutil.WriteFile(someFile, []byte("some old contents"), 0644)).To(Succeed())
+			})
+
+			It("writes an empty file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(BeEmpty())
+			})
+		})
+
+		Context("when writing fails", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(someFile, []byte("some old contents"), 0644)).To(Succeed())
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some old contents")))
+			})
+		})
+	})
+
+	Describe("#InsertLineAt", func() {
+		var (
+			lineNum   int
+			insertErr error
+		)
+
+		BeforeEach(func() {
+			lineNum = 2
+			Expect(ioutil.WriteFile(someFile, []byte("one\ntwo\nthree\n"), 0644)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			insertErr = fileThing.InsertLineAt(lineNum, "new")
+		})
+
+		It("does not return an error", func() {
+			Expect(insertErr).NotTo(HaveOccurred())
+		})
+
+		It("inserts the line before lineNum", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\nnew\ntwo\nthree\n")))
+		})
+
+		Context("when lineNum is 1", func() {
+			BeforeEach(func() {
+				lineNum = 1
+			})
+
+			It("prepends the line", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("new\none\ntwo\nthree\n")))
+			})
+		})
+
+		Context("when lineNum is past the end of the file", func() {
+			BeforeEach(func() {
+				lineNum = 10
+			})
+
+			It("appends the line", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\nnew\n")))
+			})
+		})
+
+		Context("when the file has no trailing newline", func() {
+			BeforeEach(func() {
+				lineNum = 10
+				Expect(ioutil.WriteFile(someFile, []byte("one\ntwo"), 0644)).To(Succeed())
+			})
+
+			It("does not add one", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nnew")))
+			})
+		})
+
+		Context("when the file is empty", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(someFile, []byte{}, 0644)).To(Succeed())
+			})
+
+			It("writes the line", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("new\n")))
+			})
+		})
+
+		Context("when lineNum is not positive", func() {
+			var readCalled bool
+
+			BeforeEach(func() {
+				lineNum = 0
+				readCalled = false
+				fileThing.read = func(string) ([]byte, error) {
+					readCalled = true
+					return nil, nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(insertErr).To(MatchError("insert " + someFile + ": invalid line number 0"))
+			})
+
+			It("does not read the file", func() {
+				Expect(readCalled).To(BeFalse())
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.read = failToRead
+			})
+
+			It("reports the correct error", func() {
+				Expect(insertErr).To(MatchError("read " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when writing fails", func() {
+			BeforeEach(func() {
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(insertErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\n")))
+			})
+		})
+	})
+
+	Describe("#DeleteLineAt", func() {
+		var (
+			lineNum   int
+			deleteErr error
+		)
+
+		BeforeEach(func() {
+			lineNum = 2
+			Expect(ioutil.WriteFile(someFile, []byte("one\ntwo\nthree\n"), 0644)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			deleteErr = fileThing.DeleteLineAt(lineNum)
+		})
+
+		It("does not return an error", func() {
+			Expect(deleteErr).NotTo(HaveOccurred())
+		})
+
+		It("removes a middle line", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\nthree\n")))
+		})
+
+		Context("when lineNum is 1", func() {
+			BeforeEach(func() {
+				lineNum = 1
+			})
+
+			It("removes the first line", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("two\nthree\n")))
+			})
+		})
+
+		Context("when lineNum is the last line", func() {
+			BeforeEach(func() {
+				lineNum = 3
+			})
+
+			It("removes the last line", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\n")))
+			})
+		})
+
+		Context("when the only line is removed", func() {
+			BeforeEach(func() {
+				lineNum = 1
+				Expect(ioutil.WriteFile(someFile, []byte("one\n"), 0644)).To(Succeed())
+			})
+
+			It("leaves an empty file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(BeEmpty())
+			})
+		})
+
+		Context("when lineNum is past the end of the file", func() {
+			BeforeEach(func() {
+				lineNum = 4
+			})
+
+			It("reports the correct error", func() {
+				Expect(deleteErr).To(MatchError("delete " + someFile + ": line 4 out of range (file has 3 lines)"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\n")))
+			})
+		})
+
+		Context("when lineNum is not positive", func() {
+			BeforeEach(func() {
+				lineNum = 0
+			})
+
+			It("reports the correct error", func() {
+				Expect(deleteErr).To(MatchError("delete " + someFile + ": line 0 out of range (file has 3 lines)"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.read = failToRead
+			})
+
+			It("reports the correct error", func() {
+				Expect(deleteErr).To(MatchError("read " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when writing fails", func() {
+			BeforeEach(func() {
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(deleteErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\n")))
+			})
+		})
+	})
+
+	Describe("#FilterLines", func() {
+		var (
+			keep      func(string) bool
+			removed   int
+			filterErr error
+		)
+
+		BeforeEach(func() {
+			keep = func(line string) bool {
+				return strings.HasPrefix(line, "t")
+			}
+			Expect(ioutil.WriteFile(someFile, []byte("one\ntwo\nthree\nfour\n"), 0644)).To(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			removed, filterErr = fileThing.FilterLines(keep)
+		})
+
+		It("does not return an error", func() {
+			Expect(filterErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the number of lines removed", func() {
+			Expect(removed).To(Equal(2))
+		})
+
+		It("keeps only the matching lines", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("two\nthree\n")))
+		})
+
+		Context("when the file has Windows line endings", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(someFile, []byte("one\r\ntwo\r\nthree\r\n"), 0644)).To(Succeed())
+			})
+
+			It("passes lines without terminators and preserves them on kept lines", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("two\r\nthree\r\n")))
+			})
+		})
+
+		Context("when the last line has no trailing newline", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(someFile, []byte("one\ntwo"), 0644)).To(Succeed())
+			})
+
+			It("still considers it", func() {
+				Expect(removed).To(Equal(1))
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("two")))
+			})
+		})
+
+		Context("when every line is kept", func() {
+			var writeCalled bool
+
+			BeforeEach(func() {
+				writeCalled = false
+				keep = func(string) bool {
+					return true
+				}
+				fileThing.createTemp = func(string, string) (*os.File, error) {
+					writeCalled = true
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports no lines removed", func() {
+				Expect(removed).To(BeZero())
+			})
+
+			It("does not rewrite the file", func() {
+				Expect(writeCalled).To(BeFalse())
+			})
+		})
+
+		Context("when no line is kept", func() {
+			BeforeEach(func() {
+				keep = func(string) bool {
+					return false
+				}
+			})
+
+			It("reports every line removed", func() {
+				Expect(removed).To(Equal(4))
+			})
+
+			It("leaves an empty file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(BeEmpty())
+			})
+		})
+
+		Context("when the opener is stubbed", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("a\nb\nc\n")
+				keep = func(line string) bool {
+					return line != "b"
+				}
+			})
+
+			It("filters the streamed contents", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("a\nc\n")))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(filterErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(io.MultiReader(strings.NewReader("one\n"), failingReader{})), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(filterErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\nfour\n")))
+			})
+		})
+
+		Context("when writing fails", func() {
+			BeforeEach(func() {
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(filterErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\nfour\n")))
+			})
+		})
+	})
+})
+
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error {
+	return nil
+}
+
+func openSeekableString(contents string) SeekOpener {
+	return func(string) (io.ReadSeekCloser, error) {
+		return readSeekNopCloser{strings.NewReader(contents)}, nil
+	}
+}
+
+func failToOpenSeeker(path string) (io.ReadSeekCloser, error) {
+	return nil, errors.New("I failed")
+}
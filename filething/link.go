@@ -0,0 +1,52 @@
+package filething
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+func (fileThing FileThing) SymlinkTo(linkPath string) (FileThing, error) {
+	if fileThing.dryRun("symlink") {
+		return fileThing.withPath(linkPath), nil
+	}
+	err := fileThing.symlink(fileThing.Path, linkPath)
+	if os.IsExist(err) {
+		return FileThing{}, &PathError{Op: "symlink", Path: linkPath, Err: fmt.Errorf("link path already exists: %w", os.ErrExist)}
+	}
+	if err != nil {
+		return FileThing{}, err
+	}
+	return fileThing.withPath(linkPath), nil
+}
+
+func (fileThing FileThing) LinkTo(linkPath string) (FileThing, error) {
+	if fileThing.dryRun("link") {
+		return fileThing.withPath(linkPath), nil
+	}
+	err := fileThing.link(fileThing.Path, linkPath)
+	if errors.Is(err, syscall.EXDEV) {
+		return FileThing{}, &PathError{Op: "link", Path: linkPath, Err: fmt.Errorf("cross-device hard link not supported: %w", err)}
+	}
+	if err != nil {
+		return FileThing{}, err
+	}
+	return fileThing.withPath(linkPath), nil
+}
+
+func (fileThing FileThing) ResolveSymlink() (FileThing, error) {
+	target, err := fileThing.readlink(fileThing.Path)
+	if errors.Is(err, syscall.EINVAL) {
+		return FileThing{}, &PathError{Op: "resolve", Path: fileThing.Path, Err: fmt.Errorf("not a symlink: %w", err)}
+	}
+	if err != nil {
+		return FileThing{}, err
+	}
+
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(fileThing.Path), target)
+	}
+	return fileThing.withPath(target), nil
+}
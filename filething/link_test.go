@@ -0,0 +1,255 @@
+package filething
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+		someLink  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		someLink = someFile + ".link"
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		os.Remove(someLink)
+		Expect(someFile).NotTo(BeAnExistingFile())
+		Expect(someLink).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#SymlinkTo", func() {
+		var (
+			link       FileThing
+			symlinkErr error
+		)
+
+		JustBeforeEach(func() {
+			link, symlinkErr = fileThing.SymlinkTo(someLink)
+		})
+
+		It("does not return an error", func() {
+			Expect(symlinkErr).NotTo(HaveOccurred())
+		})
+
+		It("creates a symlink to FileThing.Path", func() {
+			Expect(os.Readlink(someLink)).To(Equal(someFile))
+		})
+
+		It("returns a FileThing for the link", func() {
+			Expect(link.Path).To(Equal(someLink))
+		})
+
+		Context("when the symlinker is stubbed", func() {
+			var oldname, newname string
+
+			BeforeEach(func() {
+				fileThing.symlink = func(old, new string) error {
+					oldname, newname = old, new
+					return nil
+				}
+			})
+
+			It("links from FileThing.Path", func() {
+				Expect(oldname).To(Equal(someFile))
+			})
+
+			It("links to the link path", func() {
+				Expect(newname).To(Equal(someLink))
+			})
+		})
+
+		Context("when the link path already exists", func() {
+			BeforeEach(func() {
+				fileThing.symlink = func(oldname, newname string) error {
+					return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: syscall.EEXIST}
+				}
+			})
+
+			It("returns an exists error", func() {
+				Expect(errors.Is(symlinkErr, os.ErrExist)).To(BeTrue())
+			})
+
+			It("reports a descriptive error", func() {
+				Expect(symlinkErr).To(MatchError("symlink " + someLink + ": link path already exists: file already exists"))
+			})
+		})
+
+		Context("when creating the symlink fails", func() {
+			BeforeEach(func() {
+				fileThing.symlink = failToSymlink
+			})
+
+			It("returns an error", func() {
+				Expect(symlinkErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(symlinkErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#LinkTo", func() {
+		var (
+			link    FileThing
+			linkErr error
+		)
+
+		JustBeforeEach(func() {
+			link, linkErr = fileThing.LinkTo(someLink)
+		})
+
+		It("does not return an error", func() {
+			Expect(linkErr).NotTo(HaveOccurred())
+		})
+
+		It("creates a hard link to FileThing.Path", func() {
+			fileInfo, err := os.Stat(someFile)
+			Expect(err).NotTo(HaveOccurred())
+			linkInfo, err := os.Lstat(someLink)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.SameFile(fileInfo, linkInfo)).To(BeTrue())
+		})
+
+		It("returns a FileThing for the link", func() {
+			Expect(link.Path).To(Equal(someLink))
+		})
+
+		Context("when the linker is stubbed", func() {
+			var oldname, newname string
+
+			BeforeEach(func() {
+				fileThing.link = func(old, new string) error {
+					oldname, newname = old, new
+					return nil
+				}
+			})
+
+			It("links from FileThing.Path", func() {
+				Expect(oldname).To(Equal(someFile))
+			})
+
+			It("links to the link path", func() {
+				Expect(newname).To(Equal(someLink))
+			})
+		})
+
+		Context("when the link path is on another device", func() {
+			BeforeEach(func() {
+				fileThing.link = func(oldname, newname string) error {
+					return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: syscall.EXDEV}
+				}
+			})
+
+			It("returns a cross-device error", func() {
+				Expect(errors.Is(linkErr, syscall.EXDEV)).To(BeTrue())
+			})
+
+			It("reports a descriptive error", func() {
+				Expect(linkErr).To(MatchError(ContainSubstring("link " + someLink + ": cross-device hard link not supported")))
+			})
+		})
+
+		Context("when creating the link fails", func() {
+			BeforeEach(func() {
+				fileThing.link = failToLink
+			})
+
+			It("reports the correct error", func() {
+				Expect(linkErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#ResolveSymlink", func() {
+		var (
+			resolved   FileThing
+			resolveErr error
+		)
+
+		BeforeEach(func() {
+			err := os.Symlink(someFile, someLink)
+			Expect(err).NotTo(HaveOccurred())
+			fileThing = New(someLink)
+		})
+
+		JustBeforeEach(func() {
+			resolved, resolveErr = fileThing.ResolveSymlink()
+		})
+
+		It("does not return an error", func() {
+			Expect(resolveErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for the link target", func() {
+			Expect(resolved.Path).To(Equal(someFile))
+		})
+
+		Context("when the link reader returns a relative target", func() {
+			BeforeEach(func() {
+				fileThing.readlink = func(string) (string, error) {
+					return "target", nil
+				}
+			})
+
+			It("resolves it relative to the link", func() {
+				Expect(resolved.Path).To(Equal(filepath.Join(filepath.Dir(someLink), "target")))
+			})
+		})
+
+		Context("when FileThing.Path is not a symlink", func() {
+			BeforeEach(func() {
+				fileThing.readlink = func(path string) (string, error) {
+					return "", &os.PathError{Op: "readlink", Path: path, Err: syscall.EINVAL}
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(resolveErr).To(HaveOccurred())
+			})
+
+			It("reports that FileThing.Path is not a symlink", func() {
+				Expect(resolveErr).To(MatchError(ContainSubstring("resolve " + someLink + ": not a symlink")))
+			})
+		})
+
+		Context("when reading the link fails", func() {
+			BeforeEach(func() {
+				fileThing.readlink = failToReadlink
+			})
+
+			It("returns an error", func() {
+				Expect(resolveErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(resolveErr).To(MatchError("I failed"))
+			})
+		})
+	})
+})
+
+func failToReadlink(path string) (string, error) {
+	return "", errors.New("I failed")
+}
+
+func failToSymlink(oldname, newname string) error {
+	return errors.New("I failed")
+}
+
+func failToLink(oldname, newname string) error {
+	return errors.New("I failed")
+}
@@ -0,0 +1,48 @@
+package filething
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+var ErrWouldBlock = errors.New("would block")
+
+func (fileThing FileThing) Lock() (unlock func() error, err error) {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("lock") {
+		return func() error { return nil }, nil
+	}
+	file, err := fileThing.openRaw(fileThing.Path, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fileThing.lockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, ErrWouldBlock) {
+			return nil, &PathError{Op: "lock", Path: fileThing.Path, Err: err}
+		}
+		return nil, err
+	}
+
+	var (
+		mutex    sync.Mutex
+		released bool
+	)
+	return func() error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if released {
+			return &PathError{Op: "unlock", Path: fileThing.Path, Err: errors.New("lock already released")}
+		}
+		released = true
+
+		if err := fileThing.unlockFile(file); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}, nil
+}
@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package filething
+
+import (
+	"errors"
+	"os"
+)
+
+var errLockUnsupported = errors.New("file locking is not supported on this platform")
+
+func lockFile(file *os.File) error {
+	return errLockUnsupported
+}
+
+func unlockFile(file *os.File) error {
+	return errLockUnsupported
+}
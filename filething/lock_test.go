@@ -0,0 +1,107 @@
+package filething
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Lock", func() {
+		var (
+			unlock  func() error
+			lockErr error
+		)
+
+		JustBeforeEach(func() {
+			unlock, lockErr = fileThing.Lock()
+		})
+
+		AfterEach(func() {
+			if unlock != nil {
+				unlock()
+			}
+		})
+
+		It("does not return an error", func() {
+			Expect(lockErr).NotTo(HaveOccurred())
+		})
+
+		It("returns an unlock function", func() {
+			Expect(unlock).NotTo(BeNil())
+			Expect(unlock()).To(Succeed())
+		})
+
+		It("refuses to unlock twice", func() {
+			Expect(unlock()).To(Succeed())
+			Expect(unlock()).To(MatchError("unlock " + someFile + ": lock already released"))
+		})
+
+		Context("when the lock is held by another process", func() {
+			BeforeEach(func() {
+				fileThing.lockFile = func(*os.File) error {
+					return ErrWouldBlock
+				}
+			})
+
+			It("returns a would block error", func() {
+				Expect(errors.Is(lockErr, ErrWouldBlock)).To(BeTrue())
+				Expect(lockErr).To(MatchError("lock " + someFile + ": would block"))
+			})
+
+			It("does not return an unlock function", func() {
+				Expect(unlock).To(BeNil())
+			})
+		})
+
+		Context("when locking fails", func() {
+			BeforeEach(func() {
+				fileThing.lockFile = failToLockFile
+			})
+
+			It("returns an error", func() {
+				Expect(lockErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(lockErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when unlocking fails", func() {
+			BeforeEach(func() {
+				fileThing.unlockFile = failToLockFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(unlock()).To(MatchError("I failed"))
+			})
+
+			It("does not unlock again", func() {
+				unlock()
+				Expect(unlock()).To(MatchError("unlock " + someFile + ": lock already released"))
+			})
+		})
+	})
+})
+
+func failToLockFile(file *os.File) error {
+	return errors.New("I failed")
+}
@@ -0,0 +1,148 @@
+package filething
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+func (fileThing FileThing) MoveTo(dest string) (FileThing, error) {
+	defer fileThing.invalidateExists()
+	moved := fileThing.withPath(dest)
+	if fileThing.dryRunLogf != nil {
+		fileThing.dryRunLogf("dry run: move %s to %s", fileThing.Path, dest)
+		return moved, nil
+	}
+
+	err := fileThing.rename(fileThing.Path, dest)
+	if err == nil {
+		return moved, nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return FileThing{}, err
+	}
+
+	if err := fileThing.copy(fileThing.Path, dest); err != nil {
+		return FileThing{}, &PathError{Op: "move", Path: fileThing.Path, Err: err}
+	}
+	if err := fileThing.Remove(); err != nil {
+		return moved, &PathError{Op: "move", Path: fileThing.Path, Err: fmt.Errorf("copied to %s but failed to remove source, file now exists in both places: %w", dest, err)}
+	}
+	return moved, nil
+}
+
+func (fileThing FileThing) MoveToUnique(destDir string) (FileThing, error) {
+	dest, err := fileThing.uniquePath(destDir, filepath.Base(fileThing.Path))
+	if err != nil {
+		return FileThing{}, err
+	}
+	return fileThing.MoveTo(dest)
+}
+
+// RenameNoClobber's existence check is best effort: dest can still be created
+// by someone else between the check and the rename.
+func (fileThing FileThing) RenameNoClobber(dest string) (FileThing, error) {
+	defer fileThing.invalidateExists()
+	_, err := fileThing.stat(dest)
+	if err == nil {
+		return FileThing{}, &PathError{Op: "rename", Path: dest, Err: errors.New("destination exists")}
+	}
+	if !os.IsNotExist(err) {
+		return FileThing{}, &PathError{Op: "rename", Path: dest, Err: err}
+	}
+	if fileThing.dryRunLogf != nil {
+		fileThing.dryRunLogf("dry run: rename %s to %s", fileThing.Path, dest)
+		return fileThing.withPath(dest), nil
+	}
+
+	if err := fileThing.rename(fileThing.Path, dest); err != nil {
+		return FileThing{}, err
+	}
+	return fileThing.withPath(dest), nil
+}
+
+func (fileThing FileThing) WithExtension(ext string) (FileThing, error) {
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	current := filepath.Ext(fileThing.Path)
+	if current == filepath.Base(fileThing.Path) {
+		current = ""
+	}
+	return fileThing.RenameNoClobber(strings.TrimSuffix(fileThing.Path, current) + ext)
+}
+
+// Swap copies a to a temporary file beside b, renames b over a and then renames
+// the copy over b. If the second rename fails both renames are undone, so the
+// files end up either fully swapped or unchanged. Should that recovery fail too,
+// the error names the temporary file holding a's original contents.
+func Swap(a, b FileThing) error {
+	if a.dryRunLogf != nil {
+		a.dryRunLogf("dry run: swap %s and %s", a.Path, b.Path)
+		return nil
+	}
+
+	info, err := a.stat(a.Path)
+	if err != nil {
+		return &PathError{Op: "swap", Path: a.Path, Err: err}
+	}
+
+	dir, base := filepath.Dir(b.Path), filepath.Base(b.Path)
+	temp, err := a.createTemp(dir, "."+base+".swap")
+	if err != nil {
+		return &PathError{Op: "swap", Path: a.Path, Err: err}
+	}
+	tempThing := a.withPath(temp.Name())
+
+	if err := temp.Close(); err != nil {
+		tempThing.Remove()
+		return &PathError{Op: "swap", Path: a.Path, Err: err}
+	}
+	if err := a.copy(a.Path, tempThing.Path); err != nil {
+		tempThing.Remove()
+		return &PathError{Op: "swap", Path: a.Path, Err: err}
+	}
+	if err := a.chmod(tempThing.Path, info.Mode().Perm()); err != nil {
+		tempThing.Remove()
+		return &PathError{Op: "swap", Path: a.Path, Err: err}
+	}
+	if err := a.rename(b.Path, a.Path); err != nil {
+		tempThing.Remove()
+		return &PathError{Op: "swap", Path: b.Path, Err: err}
+	}
+
+	if err := a.rename(tempThing.Path, b.Path); err != nil {
+		if rollbackErr := a.rename(a.Path, b.Path); rollbackErr != nil {
+			return &PathError{Op: "swap", Path: b.Path, Err: fmt.Errorf("%w; rollback failed, original contents of %s are in %s: %v", err, a.Path, tempThing.Path, rollbackErr)}
+		}
+		if rollbackErr := a.rename(tempThing.Path, a.Path); rollbackErr != nil {
+			return &PathError{Op: "swap", Path: b.Path, Err: fmt.Errorf("%w; rollback failed, original contents of %s are in %s: %v", err, a.Path, tempThing.Path, rollbackErr)}
+		}
+		return &PathError{Op: "swap", Path: b.Path, Err: err}
+	}
+	return nil
+}
+
+func (fileThing FileThing) uniquePath(dir, name string) (string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if stem == "" {
+		stem, ext = name, ""
+	}
+
+	candidate := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		_, err := fileThing.stat(candidate)
+		if os.IsNotExist(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", &PathError{Op: "move", Path: candidate, Err: err}
+		}
+		candidate = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+	}
+}
@@ -0,0 +1,616 @@
+package filething
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+		someDest  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		someDest = someFile + ".moved"
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		os.Remove(someDest)
+		Expect(someFile).NotTo(BeAnExistingFile())
+		Expect(someDest).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#MoveTo", func() {
+		var (
+			moved   FileThing
+			moveErr error
+		)
+
+		JustBeforeEach(func() {
+			moved, moveErr = fileThing.MoveTo(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(moveErr).NotTo(HaveOccurred())
+		})
+
+		It("moves the file", func() {
+			Expect(someFile).NotTo(BeAnExistingFile())
+			Expect(someDest).To(BeAnExistingFile())
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(moved.Path).To(Equal(someDest))
+		})
+
+		Context("when renaming FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.rename = failToRename
+			})
+
+			It("returns an error", func() {
+				Expect(moveErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(moveErr).To(MatchError("I failed"))
+			})
+
+			It("does not remove FileThing.Path", func() {
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when renaming FileThing.Path crosses devices", func() {
+			BeforeEach(func() {
+				fileThing.rename = failToRenameCrossDevice
+			})
+
+			It("does not return an error", func() {
+				Expect(moveErr).NotTo(HaveOccurred())
+			})
+
+			It("falls back to copying and removing the file", func() {
+				Expect(someFile).NotTo(BeAnExistingFile())
+				Expect(someDest).To(BeAnExistingFile())
+			})
+
+			Context("and copying FileThing.Path fails", func() {
+				BeforeEach(func() {
+					fileThing.copy = failToCopy
+				})
+
+				It("reports the correct error", func() {
+					Expect(moveErr).To(MatchError("move " + someFile + ": I failed"))
+				})
+
+				It("does not remove FileThing.Path", func() {
+					Expect(someFile).To(BeAnExistingFile())
+				})
+			})
+
+			Context("and removing FileThing.Path fails", func() {
+				BeforeEach(func() {
+					fileThing.remove = failToRemove
+				})
+
+				It("returns an error", func() {
+					Expect(moveErr).To(HaveOccurred())
+				})
+
+				It("reports that the file exists in both places", func() {
+					Expect(moveErr).To(MatchError(ContainSubstring("file now exists in both places")))
+					Expect(moveErr).To(MatchError(ContainSubstring("I failed")))
+				})
+
+				It("leaves both files in place", func() {
+					Expect(someFile).To(BeAnExistingFile())
+					Expect(someDest).To(BeAnExistingFile())
+				})
+			})
+		})
+
+		Context("when FileThing.Path has contents", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+				fileThing.rename = failToRenameCrossDevice
+			})
+
+			It("preserves them across devices", func() {
+				Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("some contents")))
+			})
+		})
+	})
+
+	Describe("#MoveToUnique", func() {
+		var (
+			someDir string
+			taken   map[string]bool
+			renamed string
+			moved   FileThing
+			moveErr error
+		)
+
+		BeforeEach(func() {
+			someDir = filepath.Join("/", "some", "dir")
+			fileThing = New(filepath.Join("/", "src", "report.txt"))
+			taken = map[string]bool{}
+			renamed = ""
+
+			fileThing.stat = func(path string) (os.FileInfo, error) {
+				if taken[path] {
+					return fakeFileInfo{}, nil
+				}
+				return statNotExist(path)
+			}
+			fileThing.rename = func(oldpath, newpath string) error {
+				renamed = newpath
+				return nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			moved, moveErr = fileThing.MoveToUnique(someDir)
+		})
+
+		It("does not return an error", func() {
+			Expect(moveErr).NotTo(HaveOccurred())
+		})
+
+		It("uses the base name as-is when it is free", func() {
+			Expect(renamed).To(Equal(filepath.Join(someDir, "report.txt")))
+			Expect(moved.Path).To(Equal(renamed))
+		})
+
+		Context("when the base name is taken", func() {
+			BeforeEach(func() {
+				taken[filepath.Join(someDir, "report.txt")] = true
+			})
+
+			It("adds a numeric suffix before the extension", func() {
+				Expect(renamed).To(Equal(filepath.Join(someDir, "report (1).txt")))
+				Expect(moved.Path).To(Equal(renamed))
+			})
+
+			Context("and so is the first suffix", func() {
+				BeforeEach(func() {
+					taken[filepath.Join(someDir, "report (1).txt")] = true
+				})
+
+				It("increments the suffix", func() {
+					Expect(renamed).To(Equal(filepath.Join(someDir, "report (2).txt")))
+				})
+			})
+		})
+
+		Context("when the name has no extension", func() {
+			BeforeEach(func() {
+				fileThing = fileThing.withPath(filepath.Join("/", "src", ".profile"))
+				taken[filepath.Join(someDir, ".profile")] = true
+			})
+
+			It("appends the suffix", func() {
+				Expect(renamed).To(Equal(filepath.Join(someDir, ".profile (1)")))
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(moveErr).To(MatchError("move " + filepath.Join(someDir, "report.txt") + ": I failed"))
+			})
+
+			It("does not move the file", func() {
+				Expect(renamed).To(BeEmpty())
+			})
+		})
+
+		Context("when renaming fails", func() {
+			BeforeEach(func() {
+				fileThing.rename = failToRename
+			})
+
+			It("reports the correct error", func() {
+				Expect(moveErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#RenameNoClobber", func() {
+		var (
+			renamed   FileThing
+			renameErr error
+		)
+
+		JustBeforeEach(func() {
+			renamed, renameErr = fileThing.RenameNoClobber(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(renameErr).NotTo(HaveOccurred())
+		})
+
+		It("renames the file", func() {
+			Expect(someFile).NotTo(BeAnExistingFile())
+			Expect(someDest).To(BeAnExistingFile())
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(renamed.Path).To(Equal(someDest))
+		})
+
+		Context("when the destination exists", func() {
+			var renameCalled bool
+
+			BeforeEach(func() {
+				renameCalled = false
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{}, nil
+				}
+				fileThing.rename = func(string, string) error {
+					renameCalled = true
+					return nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(renameErr).To(MatchError("rename " + someDest + ": destination exists"))
+			})
+
+			It("does not rename", func() {
+				Expect(renameCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the destination is a real file", func() {
+			BeforeEach(func() {
+				Expect(ioutil.WriteFile(someDest, []byte("precious"), 0644)).To(Succeed())
+			})
+
+			It("leaves both files untouched", func() {
+				Expect(renameErr).To(HaveOccurred())
+				Expect(someFile).To(BeAnExistingFile())
+				Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("precious")))
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(renameErr).To(MatchError("rename " + someDest + ": I failed"))
+			})
+
+			It("does not rename", func() {
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when renaming fails", func() {
+			BeforeEach(func() {
+				fileThing.rename = failToRename
+			})
+
+			It("reports the correct error", func() {
+				Expect(renameErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#WithExtension", func() {
+		var (
+			ext       string
+			stats     []string
+			renamedTo string
+			renamed   FileThing
+			renameErr error
+		)
+
+		BeforeEach(func() {
+			ext = ".md"
+			stats, renamedTo = nil, ""
+			fileThing = New(filepath.Join("some", "dir", "file.txt"))
+			fileThing.stat = func(path string) (os.FileInfo, error) {
+				stats = append(stats, path)
+				return statNotExist(path)
+			}
+			fileThing.rename = func(oldpath, newpath string) error {
+				renamedTo = newpath
+				return nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			renamed, renameErr = fileThing.WithExtension(ext)
+		})
+
+		It("does not return an error", func() {
+			Expect(renameErr).NotTo(HaveOccurred())
+		})
+
+		It("replaces the extension", func() {
+			Expect(renamedTo).To(Equal(filepath.Join("some", "dir", "file.md")))
+			Expect(renamed.Path).To(Equal(renamedTo))
+		})
+
+		It("checks the target does not exist", func() {
+			Expect(stats).To(Equal([]string{filepath.Join("some", "dir", "file.md")}))
+		})
+
+		Context("when ext has no leading dot", func() {
+			BeforeEach(func() {
+				ext = "md"
+			})
+
+			It("adds one", func() {
+				Expect(renamedTo).To(Equal(filepath.Join("some", "dir", "file.md")))
+			})
+		})
+
+		Context("when FileThing.Path has no extension", func() {
+			BeforeEach(func() {
+				fileThing = fileThing.withPath(filepath.Join("some", "dir.d", "file"))
+			})
+
+			It("adds the extension", func() {
+				Expect(renamedTo).To(Equal(filepath.Join("some", "dir.d", "file.md")))
+			})
+		})
+
+		Context("when FileThing.Path is a dotfile", func() {
+			BeforeEach(func() {
+				fileThing = fileThing.withPath(filepath.Join("some", "dir", ".profile"))
+			})
+
+			It("keeps the dotfile name", func() {
+				Expect(renamedTo).To(Equal(filepath.Join("some", "dir", ".profile.md")))
+			})
+		})
+
+		Context("when ext is empty", func() {
+			BeforeEach(func() {
+				ext = ""
+			})
+
+			It("strips the extension", func() {
+				Expect(renamedTo).To(Equal(filepath.Join("some", "dir", "file")))
+			})
+		})
+
+		Context("when the target exists", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{}, nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(renameErr).To(MatchError("rename " + filepath.Join("some", "dir", "file.md") + ": destination exists"))
+			})
+
+			It("does not rename", func() {
+				Expect(renamedTo).To(BeEmpty())
+			})
+		})
+
+		Context("when renaming fails", func() {
+			BeforeEach(func() {
+				fileThing.rename = failToRename
+			})
+
+			It("reports the correct error", func() {
+				Expect(renameErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("Swap", func() {
+		var (
+			someDir     string
+			a, b        FileThing
+			renameCalls int
+			failRename  map[int]bool
+			swapErr     error
+		)
+
+		BeforeEach(func() {
+			someDir = createSomeTempDir()
+			a = New(filepath.Join(someDir, "a"))
+			b = New(filepath.Join(someDir, "b"))
+			Expect(ioutil.WriteFile(a.Path, []byte("contents of a"), 0640)).To(Succeed())
+			Expect(ioutil.WriteFile(b.Path, []byte("contents of b"), 0644)).To(Succeed())
+
+			renameCalls = 0
+			failRename = map[int]bool{}
+			a.rename = func(oldpath, newpath string) error {
+				renameCalls++
+				if failRename[renameCalls] {
+					return errors.New("I failed")
+				}
+				return os.Rename(oldpath, newpath)
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		JustBeforeEach(func() {
+			swapErr = Swap(a, b)
+		})
+
+		It("does not return an error", func() {
+			Expect(swapErr).NotTo(HaveOccurred())
+		})
+
+		It("exchanges the contents", func() {
+			Expect(ioutil.ReadFile(a.Path)).To(Equal([]byte("contents of b")))
+			Expect(ioutil.ReadFile(b.Path)).To(Equal([]byte("contents of a")))
+		})
+
+		It("carries a's permissions over to b", func() {
+			info, err := os.Stat(b.Path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+		})
+
+		It("uses two renames", func() {
+			Expect(renameCalls).To(Equal(2))
+		})
+
+		It("leaves no temp file behind", func() {
+			Expect(listDir(someDir)).To(ConsistOf("a", "b"))
+		})
+
+		Context("when the first rename fails", func() {
+			BeforeEach(func() {
+				failRename[1] = true
+			})
+
+			It("reports the correct error", func() {
+				Expect(swapErr).To(MatchError("swap " + b.Path + ": I failed"))
+			})
+
+			It("leaves both files unchanged", func() {
+				Expect(ioutil.ReadFile(a.Path)).To(Equal([]byte("contents of a")))
+				Expect(ioutil.ReadFile(b.Path)).To(Equal([]byte("contents of b")))
+			})
+
+			It("leaves no temp file behind", func() {
+				Expect(listDir(someDir)).To(ConsistOf("a", "b"))
+			})
+		})
+
+		Context("when the second rename fails", func() {
+			BeforeEach(func() {
+				failRename[2] = true
+			})
+
+			It("reports the correct error", func() {
+				Expect(swapErr).To(MatchError("swap " + b.Path + ": I failed"))
+			})
+
+			It("rolls back to leave both files unchanged", func() {
+				Expect(ioutil.ReadFile(a.Path)).To(Equal([]byte("contents of a")))
+				Expect(ioutil.ReadFile(b.Path)).To(Equal([]byte("contents of b")))
+			})
+
+			It("leaves no temp file behind", func() {
+				Expect(listDir(someDir)).To(ConsistOf("a", "b"))
+			})
+
+			Context("and rolling back fails", func() {
+				BeforeEach(func() {
+					failRename[3] = true
+				})
+
+				It("reports where a's contents were left", func() {
+					Expect(swapErr).To(MatchError(MatchRegexp(`rollback failed, original contents of .*a are in .*\.b\.swap`)))
+				})
+
+				It("keeps a's contents in the temp file", func() {
+					var temps []string
+					for _, name := range listDir(someDir) {
+						if name != "a" && name != "b" {
+							temps = append(temps, name)
+						}
+					}
+					Expect(temps).To(HaveLen(1))
+					Expect(ioutil.ReadFile(filepath.Join(someDir, temps[0]))).To(Equal([]byte("contents of a")))
+				})
+			})
+		})
+
+		Context("when creating the temp file fails", func() {
+			BeforeEach(func() {
+				a.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(swapErr).To(MatchError("swap " + a.Path + ": I failed"))
+			})
+
+			It("does not rename anything", func() {
+				Expect(renameCalls).To(BeZero())
+			})
+		})
+
+		Context("when b has no directory part", func() {
+			var tempDir string
+
+			BeforeEach(func() {
+				tempDir = ""
+				a, b = New("a"), New("b")
+				a.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{name: "a", mode: 0644}, nil
+				}
+				a.createTemp = func(dir, pattern string) (*os.File, error) {
+					tempDir = dir
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("creates the temp file in the current directory", func() {
+				Expect(tempDir).To(Equal("."))
+			})
+		})
+
+		Context("when copying a fails", func() {
+			BeforeEach(func() {
+				a.copy = failToCopy
+			})
+
+			It("reports the correct error", func() {
+				Expect(swapErr).To(MatchError("swap " + a.Path + ": I failed"))
+			})
+
+			It("leaves both files unchanged", func() {
+				Expect(ioutil.ReadFile(a.Path)).To(Equal([]byte("contents of a")))
+				Expect(ioutil.ReadFile(b.Path)).To(Equal([]byte("contents of b")))
+			})
+
+			It("leaves no temp file behind", func() {
+				Expect(listDir(someDir)).To(ConsistOf("a", "b"))
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				a.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(swapErr).To(MatchError("swap " + a.Path + ": I failed"))
+			})
+
+			It("does not rename anything", func() {
+				Expect(renameCalls).To(BeZero())
+			})
+		})
+	})
+})
+
+func failToRename(oldpath, newpath string) error {
+	return errors.New("I failed")
+}
+
+func failToRenameCrossDevice(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+}
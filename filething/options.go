@@ -0,0 +1,34 @@
+package filething
+
+type Option func(*FileThing)
+
+type Hooks struct {
+	BeforeRemove func(path string)
+	AfterRemove  func(path string, err error)
+}
+
+func WithRemover(remover Remover) Option {
+	return func(fileThing *FileThing) {
+		fileThing.remove = remover
+	}
+}
+
+func WithDryRun(logf Logf) Option {
+	return func(fileThing *FileThing) {
+		fileThing.dryRunLogf = logf
+	}
+}
+
+// WithExistenceCache caches the first successful Exists result until a method
+// that creates, removes or moves FileThing.Path runs.
+func WithExistenceCache() Option {
+	return func(fileThing *FileThing) {
+		fileThing.existCache = new(existsCache)
+	}
+}
+
+func WithHooks(hooks Hooks) Option {
+	return func(fileThing *FileThing) {
+		fileThing.hooks = hooks
+	}
+}
@@ -0,0 +1,603 @@
+package filething
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var someFile string
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("New", func() {
+		Context("when no options are given", func() {
+			It("uses the default remover", func() {
+				Expect(New(someFile).Remove()).To(Succeed())
+				Expect(someFile).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("WithRemover", func() {
+		var (
+			fileThing   FileThing
+			removedPath string
+		)
+
+		BeforeEach(func() {
+			removedPath = ""
+			fileThing = New(someFile, WithRemover(func(path string) error {
+				removedPath = path
+				return nil
+			}))
+		})
+
+		It("makes Remove call the custom remover", func() {
+			Expect(fileThing.Remove()).To(Succeed())
+			Expect(removedPath).To(Equal(someFile))
+		})
+
+		It("does not use the default remover", func() {
+			Expect(fileThing.Remove()).To(Succeed())
+			Expect(someFile).To(BeAnExistingFile())
+		})
+
+		Context("when the custom remover fails", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile, WithRemover(failToRemove))
+			})
+
+			It("reports the correct error", func() {
+				Expect(fileThing.Remove()).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("WithDryRun", func() {
+		var (
+			fileThing FileThing
+			messages  []string
+			touched   []string
+		)
+
+		BeforeEach(func() {
+			messages = nil
+			touched = nil
+
+			fileThing = New(someFile, WithDryRun(func(format string, args ...interface{}) {
+				messages = append(messages, fmt.Sprintf(format, args...))
+			}))
+
+			fileThing.remove = func(path string) error {
+				touched = append(touched, "remove")
+				return nil
+			}
+			fileThing.removeAll = func(path string) error {
+				touched = append(touched, "removeAll")
+				return nil
+			}
+			fileThing.rename = func(oldpath, newpath string) error {
+				touched = append(touched, "rename")
+				return nil
+			}
+			fileThing.copy = func(src, dst string) error {
+				touched = append(touched, "copy")
+				return nil
+			}
+			fileThing.write = func(string, []byte, os.FileMode) error {
+				touched = append(touched, "write")
+				return nil
+			}
+			fileThing.createTemp = func(dir, pattern string) (*os.File, error) {
+				touched = append(touched, "createTemp")
+				return nil, errors.New("I failed")
+			}
+			fileThing.openFile = func(string, int, os.FileMode) (io.WriteCloser, error) {
+				touched = append(touched, "openFile")
+				return nil, errors.New("I failed")
+			}
+			fileThing.truncate = func(string, int64) error {
+				touched = append(touched, "truncate")
+				return nil
+			}
+			fileThing.create = func(string) (io.WriteCloser, error) {
+				touched = append(touched, "create")
+				return nil, errors.New("I failed")
+			}
+			fileThing.chmod = func(string, os.FileMode) error {
+				touched = append(touched, "chmod")
+				return errors.New("I failed")
+			}
+			fileThing.chown = func(string, int, int) error {
+				touched = append(touched, "chown")
+				return errors.New("I failed")
+			}
+			fileThing.chtimes = func(string, time.Time, time.Time) error {
+				touched = append(touched, "chtimes")
+				return errors.New("I failed")
+			}
+			fileThing.mkdirAll = func(string, os.FileMode) error {
+				touched = append(touched, "mkdirAll")
+				return errors.New("I failed")
+			}
+			fileThing.symlink = func(string, string) error {
+				touched = append(touched, "symlink")
+				return errors.New("I failed")
+			}
+			fileThing.link = func(string, string) error {
+				touched = append(touched, "link")
+				return errors.New("I failed")
+			}
+			fileThing.openRaw = func(string, int, os.FileMode) (*os.File, error) {
+				touched = append(touched, "openRaw")
+				return nil, errors.New("I failed")
+			}
+			fileThing.open = func(string) (io.ReadCloser, error) {
+				touched = append(touched, "open")
+				return nil, errors.New("I failed")
+			}
+			fileThing.openSeeker = func(string) (io.ReadSeekCloser, error) {
+				touched = append(touched, "openSeeker")
+				return nil, errors.New("I failed")
+			}
+			fileThing.walk = func(string, filepath.WalkFunc) error {
+				touched = append(touched, "walk")
+				return errors.New("I failed")
+			}
+		})
+
+		Describe("#Remove", func() {
+			It("does not call the remover", func() {
+				Expect(fileThing.Remove()).To(Succeed())
+				Expect(touched).To(BeEmpty())
+			})
+
+			It("logs the removal", func() {
+				Expect(fileThing.Remove()).To(Succeed())
+				Expect(messages).To(Equal([]string{"dry run: remove " + someFile}))
+			})
+		})
+
+		Describe("#WriteAtomic", func() {
+			It("does not write anything", func() {
+				Expect(fileThing.WriteAtomic([]byte("some contents"))).To(Succeed())
+				Expect(touched).To(BeEmpty())
+			})
+
+			It("logs the write", func() {
+				Expect(fileThing.WriteAtomic([]byte("some contents"))).To(Succeed())
+				Expect(messages).To(Equal([]string{"dry run: write " + someFile}))
+			})
+		})
+
+		Describe("#MoveTo", func() {
+			It("does not move anything", func() {
+				moved, err := fileThing.MoveTo(someFile + ".moved")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(moved.Path).To(Equal(someFile + ".moved"))
+				Expect(touched).To(BeEmpty())
+			})
+
+			It("logs the move", func() {
+				_, err := fileThing.MoveTo(someFile + ".moved")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(messages).To(Equal([]string{"dry run: move " + someFile + " to " + someFile + ".moved"}))
+			})
+		})
+
+		Describe("#RenameNoClobber", func() {
+			It("does not rename anything", func() {
+				renamed, err := fileThing.RenameNoClobber(someFile + ".renamed")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(renamed.Path).To(Equal(someFile + ".renamed"))
+				Expect(touched).To(BeEmpty())
+				Expect(someFile).To(BeAnExistingFile())
+			})
+
+			It("logs the rename", func() {
+				_, err := fileThing.RenameNoClobber(someFile + ".renamed")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(messages).To(Equal([]string{"dry run: rename " + someFile + " to " + someFile + ".renamed"}))
+			})
+
+			It("still reports an existing destination", func() {
+				_, err := fileThing.RenameNoClobber(someFile)
+				Expect(err).To(MatchError("rename " + someFile + ": destination exists"))
+				Expect(messages).To(BeEmpty())
+			})
+		})
+
+		Describe("#WithExtension", func() {
+			It("does not rename anything", func() {
+				renamed, err := fileThing.WithExtension("bak")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(renamed.Ext()).To(Equal(".bak"))
+				Expect(touched).To(BeEmpty())
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+
+		It("covers the other destructive methods", func() {
+			Expect(fileThing.Write([]byte("some contents"))).To(Succeed())
+			Expect(fileThing.RemoveAll()).To(Succeed())
+			Expect(fileThing.RemoveWithBackup(someFile + ".bak")).To(Succeed())
+			Expect(fileThing.SecureRemove()).To(Succeed())
+			Expect(fileThing.Truncate(0)).To(Succeed())
+			Expect(Swap(fileThing, New(someFile+".other"))).To(Succeed())
+
+			Expect(touched).To(BeEmpty())
+			Expect(messages).To(Equal([]string{
+				"dry run: write " + someFile,
+				"dry run: removeall " + someFile,
+				"dry run: remove " + someFile,
+				"dry run: secureremove " + someFile,
+				"dry run: truncate " + someFile,
+				"dry run: swap " + someFile + " and " + someFile + ".other",
+			}))
+		})
+
+		for _, method := range []struct {
+			name    string
+			call    func(FileThing) error
+			message func(path string) string
+		}{
+			{"Append", func(f FileThing) error { return f.Append([]byte("some contents")) }, prefixed("append")},
+			{"AppendLine", func(f FileThing) error { return f.AppendLine("some line") }, prefixed("append")},
+			{"ReadFrom", func(f FileThing) error {
+				_, err := f.ReadFrom(strings.NewReader("some contents"))
+				return err
+			}, prefixed("write")},
+			{"WriteSynced", func(f FileThing) error { return f.WriteSynced([]byte("some contents")) }, prefixed("write")},
+			{"Create", func(f FileThing) error { return f.Create() }, prefixed("create")},
+			{"CreateOrTruncate", func(f FileThing) error { return f.CreateOrTruncate() }, prefixed("create")},
+			{"Touch", func(f FileThing) error { return f.Touch() }, prefixed("touch")},
+			{"Chmod", func(f FileThing) error { return f.Chmod(0600) }, prefixed("chmod")},
+			{"Chown", func(f FileThing) error { return f.Chown(0, 0) }, prefixed("chown")},
+			{"Mkdir", func(f FileThing) error { return f.Mkdir(0755) }, prefixed("mkdir")},
+			{"Copy", func(f FileThing) error {
+				_, err := f.Copy(f.Path + ".copy")
+				return err
+			}, func(path string) string { return "dry run: copy " + path + " to " + path + ".copy" }},
+			{"CopyPreserving", func(f FileThing) error {
+				_, err := f.CopyPreserving(f.Path + ".copy")
+				return err
+			}, func(path string) string { return "dry run: copy " + path + " to " + path + ".copy" }},
+			{"DuplicateInto", func(f FileThing) error {
+				_, err := f.DuplicateInto(filepath.Join(f.Path+".dir", "sub"))
+				return err
+			}, func(path string) string {
+				return "dry run: copy " + path + " to " + filepath.Join(path+".dir", "sub", filepath.Base(path))
+			}},
+			{"CopyTree", func(f FileThing) error {
+				_, err := f.CopyTree(f.Path + ".tree")
+				return err
+			}, prefixed("copytree")},
+			{"Mirror", func(f FileThing) error { return f.Mirror(f.Path + ".mirror") }, prefixed("mirror")},
+			{"CopyWithProgress", func(f FileThing) error {
+				_, err := f.CopyWithProgress(f.Path+".copy", func(int64) {})
+				return err
+			}, prefixed("copy")},
+			{"CopyAndChecksum", func(f FileThing) error {
+				_, _, err := f.CopyAndChecksum(f.Path + ".copy")
+				return err
+			}, prefixed("copy")},
+			{"Process", func(f FileThing) error {
+				_, err := f.Process(func(r io.Reader) (io.Reader, error) { return r, nil }, f.Path+".processed")
+				return err
+			}, prefixed("process")},
+			{"Compress", func(f FileThing) error {
+				_, err := f.Compress(f.Path + ".gz")
+				return err
+			}, prefixed("compress")},
+			{"Decompress", func(f FileThing) error {
+				_, err := f.Decompress(f.Path + ".out")
+				return err
+			}, prefixed("decompress")},
+			{"Encrypt", func(f FileThing) error {
+				_, err := f.Encrypt(make([]byte, 32), f.Path+".enc")
+				return err
+			}, prefixed("encrypt")},
+			{"Decrypt", func(f FileThing) error {
+				_, err := f.Decrypt(make([]byte, 32), f.Path+".dec")
+				return err
+			}, prefixed("decrypt")},
+			{"Split", func(f FileThing) error {
+				_, err := f.Split(1, f.Path+".chunks")
+				return err
+			}, prefixed("split")},
+			{"Tar", func(f FileThing) error {
+				_, err := f.Tar(f.Path + ".tar")
+				return err
+			}, prefixed("tar")},
+			{"Untar", func(f FileThing) error { return f.Untar(f.Path + ".untarred") }, prefixed("untar")},
+			{"Zip", func(f FileThing) error {
+				_, err := f.Zip(f.Path + ".zip")
+				return err
+			}, prefixed("zip")},
+			{"Unzip", func(f FileThing) error { return f.Unzip(f.Path + ".unzipped") }, prefixed("unzip")},
+			{"SymlinkTo", func(f FileThing) error {
+				_, err := f.SymlinkTo(f.Path + ".sym")
+				return err
+			}, prefixed("symlink")},
+			{"LinkTo", func(f FileThing) error {
+				_, err := f.LinkTo(f.Path + ".link")
+				return err
+			}, prefixed("link")},
+			{"Lock", func(f FileThing) error {
+				unlock, err := f.Lock()
+				if err != nil {
+					return err
+				}
+				return unlock()
+			}, prefixed("lock")},
+		} {
+			method := method
+
+			Describe("#"+method.name, func() {
+				It("does not touch the filesystem", func() {
+					Expect(method.call(fileThing)).To(Succeed())
+					Expect(touched).To(BeEmpty())
+				})
+
+				It("logs the operation", func() {
+					Expect(method.call(fileThing)).To(Succeed())
+					Expect(messages).To(Equal([]string{method.message(someFile)}))
+				})
+			})
+		}
+
+		It("carries over to derived FileThings", func() {
+			Expect(fileThing.Dir().Remove()).To(Succeed())
+			Expect(touched).To(BeEmpty())
+			Expect(messages).To(HaveLen(1))
+		})
+	})
+
+	Describe("WithHooks", func() {
+		var (
+			fileThing FileThing
+			calls     []string
+			afterErr  error
+		)
+
+		BeforeEach(func() {
+			calls = nil
+			afterErr = nil
+			fileThing = New(someFile, WithHooks(Hooks{
+				BeforeRemove: func(path string) {
+					calls = append(calls, "before "+path)
+				},
+				AfterRemove: func(path string, err error) {
+					calls = append(calls, "after "+path)
+					afterErr = err
+				},
+			}))
+		})
+
+		It("calls the hooks around a successful remove", func() {
+			Expect(fileThing.Remove()).To(Succeed())
+			Expect(calls).To(Equal([]string{"before " + someFile, "after " + someFile}))
+			Expect(afterErr).NotTo(HaveOccurred())
+		})
+
+		It("calls the before hook before removing", func() {
+			fileThing.remove = func(path string) error {
+				calls = append(calls, "remove "+path)
+				return nil
+			}
+			Expect(fileThing.Remove()).To(Succeed())
+			Expect(calls).To(Equal([]string{"before " + someFile, "remove " + someFile, "after " + someFile}))
+		})
+
+		Context("when the remove fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = failToRemove
+			})
+
+			It("passes the error to the after hook", func() {
+				Expect(fileThing.Remove()).To(MatchError("I failed"))
+				Expect(calls).To(Equal([]string{"before " + someFile, "after " + someFile}))
+				Expect(afterErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing.remove = removeNotExist
+			})
+
+			It("passes the same nil error that Remove returns", func() {
+				Expect(fileThing.Remove()).To(Succeed())
+				Expect(afterErr).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when only some hooks are set", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile, WithHooks(Hooks{
+					AfterRemove: func(path string, err error) {
+						calls = append(calls, "after "+path)
+					},
+				}))
+			})
+
+			It("calls the hooks that are set", func() {
+				Expect(fileThing.Remove()).To(Succeed())
+				Expect(calls).To(Equal([]string{"after " + someFile}))
+			})
+		})
+	})
+
+	Describe("WithExistenceCache", func() {
+		var (
+			fileThing FileThing
+			stats     int
+		)
+
+		BeforeEach(func() {
+			stats = 0
+			fileThing = New(someFile, WithExistenceCache())
+			stat := fileThing.stat
+			fileThing.stat = func(path string) (os.FileInfo, error) {
+				stats++
+				return stat(path)
+			}
+		})
+
+		It("stats FileThing.Path once across repeated Exists calls", func() {
+			Expect(fileThing.Exists()).To(BeTrue())
+			Expect(fileThing.Exists()).To(BeTrue())
+			Expect(stats).To(Equal(1))
+		})
+
+		It("shares the cache between copies", func() {
+			Expect(fileThing.Exists()).To(BeTrue())
+			copied := fileThing
+			Expect(copied.Exists()).To(BeTrue())
+			Expect(stats).To(Equal(1))
+		})
+
+		It("does not share the cache with derived FileThings", func() {
+			Expect(fileThing.Exists()).To(BeTrue())
+			derived := fileThing.withPath(someFile + ".missing")
+			Expect(derived.Exists()).To(BeFalse())
+			Expect(stats).To(Equal(2))
+		})
+
+		Context("when FileThing.Path is removed", func() {
+			It("stats again after the remove", func() {
+				Expect(fileThing.Exists()).To(BeTrue())
+				Expect(fileThing.Remove()).To(Succeed())
+				Expect(fileThing.Exists()).To(BeFalse())
+				Expect(stats).To(Equal(2))
+			})
+		})
+
+		Context("when FileThing.Path is written", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(someFile)).To(Succeed())
+			})
+
+			It("stats again after the write", func() {
+				Expect(fileThing.Exists()).To(BeFalse())
+				Expect(fileThing.Write([]byte("some contents"))).To(Succeed())
+				Expect(fileThing.Exists()).To(BeTrue())
+				Expect(stats).To(Equal(2))
+			})
+		})
+
+		Context("when FileThing.Path is moved", func() {
+			AfterEach(func() {
+				os.Remove(someFile + ".moved")
+			})
+
+			It("stats again after the move", func() {
+				Expect(fileThing.Exists()).To(BeTrue())
+				_, err := fileThing.MoveTo(someFile + ".moved")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fileThing.Exists()).To(BeFalse())
+				Expect(stats).To(Equal(2))
+			})
+		})
+
+		Context("when FileThing.Path is created by locking it", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(someFile)).To(Succeed())
+			})
+
+			It("stats again after the lock", func() {
+				Expect(fileThing.Exists()).To(BeFalse())
+				unlock, err := fileThing.Lock()
+				Expect(err).NotTo(HaveOccurred())
+				defer unlock()
+				Expect(fileThing.Exists()).To(BeTrue())
+				Expect(stats).To(Equal(2))
+			})
+		})
+
+		Context("when FileThing.Path is created by opening it", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(someFile)).To(Succeed())
+			})
+
+			It("stats again after the open", func() {
+				Expect(fileThing.Exists()).To(BeFalse())
+				file, err := fileThing.Open(os.O_CREATE|os.O_WRONLY, 0644)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(file.Close()).To(Succeed())
+				Expect(fileThing.Exists()).To(BeTrue())
+				Expect(stats).To(Equal(2))
+			})
+		})
+
+		Context("when FileThing.Path is created by something else", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(someFile)).To(Succeed())
+			})
+
+			It("is still seen by WaitForExists", func() {
+				Expect(fileThing.Exists()).To(BeFalse())
+				Expect(ioutil.WriteFile(someFile, []byte{}, 0644)).To(Succeed())
+
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				Expect(fileThing.WaitForExists(ctx, time.Millisecond)).To(Succeed())
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					stats++
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("does not cache the failure", func() {
+				Expect(fileThing.Exists()).Error().To(MatchError("I failed"))
+				Expect(fileThing.Exists()).Error().To(MatchError("I failed"))
+				Expect(stats).To(Equal(2))
+			})
+		})
+
+		Context("when the option is not given", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile)
+				fileThing.stat = func(path string) (os.FileInfo, error) {
+					stats++
+					return os.Stat(path)
+				}
+			})
+
+			It("stats on every call", func() {
+				Expect(fileThing.Exists()).To(BeTrue())
+				Expect(fileThing.Exists()).To(BeTrue())
+				Expect(stats).To(Equal(2))
+			})
+		})
+	})
+})
+
+func prefixed(op string) func(path string) string {
+	return func(path string) string {
+		return "dry run: " + op + " " + path
+	}
+}
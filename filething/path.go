@@ -0,0 +1,159 @@
+package filething
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func NewValidated(path string, opts ...Option) (FileThing, error) {
+	if path == "" {
+		return FileThing{}, &PathError{Op: "validate", Path: path, Err: errors.New("path is empty")}
+	}
+	if strings.ContainsRune(path, 0) {
+		return FileThing{}, &PathError{Op: "validate", Path: path, Err: errors.New("path contains a null byte")}
+	}
+	return New(path, opts...), nil
+}
+
+func NewTemp(dir, pattern string, opts ...Option) (FileThing, error) {
+	fileThing := New(dir, opts...)
+	file, err := fileThing.createTemp(dir, pattern)
+	if err != nil {
+		return FileThing{}, err
+	}
+
+	fileThing = fileThing.withPath(file.Name())
+	if err := file.Close(); err != nil {
+		fileThing.Remove()
+		return FileThing{}, err
+	}
+	return fileThing, nil
+}
+
+func NewExpanded(path string, opts ...Option) (FileThing, error) {
+	fileThing := New(path, opts...)
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return fileThing, nil
+	}
+
+	home, err := fileThing.userHomeDir()
+	if err != nil {
+		return FileThing{}, &PathError{Op: "expand", Path: path, Err: err}
+	}
+	return fileThing.withPath(filepath.Join(home, strings.TrimPrefix(path, "~"))), nil
+}
+
+func NewFromEnv(path string, opts ...Option) FileThing {
+	fileThing := New(path, opts...)
+	return fileThing.withPath(os.Expand(path, func(name string) string {
+		value, _ := fileThing.lookupEnv(name)
+		return value
+	}))
+}
+
+func NewFromEnvStrict(path string, opts ...Option) (FileThing, error) {
+	var (
+		fileThing = New(path, opts...)
+		unset     []string
+	)
+	expanded := os.Expand(path, func(name string) string {
+		value, ok := fileThing.lookupEnv(name)
+		if !ok {
+			unset = append(unset, name)
+		}
+		return value
+	})
+
+	if len(unset) > 0 {
+		return FileThing{}, &PathError{Op: "expand", Path: path, Err: fmt.Errorf("environment variable %s is not set", strings.Join(unset, ", "))}
+	}
+	return fileThing.withPath(expanded), nil
+}
+
+func NewFromURL(url, destPath string, opts ...Option) (FileThing, error) {
+	fileThing := New(destPath, opts...)
+	response, err := fileThing.httpGet(url)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "download", Path: url, Err: err}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return FileThing{}, &PathError{Op: "download", Path: url, Err: fmt.Errorf("unexpected status %s", response.Status)}
+	}
+
+	if err := fileThing.download(response.Body); err != nil {
+		return FileThing{}, &PathError{Op: "download", Path: url, Err: err}
+	}
+	return fileThing, nil
+}
+
+// download writes body to a sibling temp file and renames it into place, so a
+// failed download never touches whatever is already at FileThing.Path.
+func (fileThing FileThing) download(body io.Reader) error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("download") {
+		return nil
+	}
+	dir, base := filepath.Dir(fileThing.Path), filepath.Base(fileThing.Path)
+	temp, err := fileThing.createTemp(dir, "."+base+".download")
+	if err != nil {
+		return err
+	}
+	tempThing := fileThing.withPath(temp.Name())
+
+	if _, err := io.Copy(temp, body); err != nil {
+		temp.Close()
+		tempThing.Remove()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		tempThing.Remove()
+		return err
+	}
+	if err := fileThing.chmod(tempThing.Path, 0644); err != nil {
+		tempThing.Remove()
+		return err
+	}
+	if err := fileThing.rename(tempThing.Path, fileThing.Path); err != nil {
+		tempThing.Remove()
+		return err
+	}
+	return nil
+}
+
+func NewClean(path string, opts ...Option) FileThing {
+	return New(filepath.Clean(path), opts...)
+}
+
+func (fileThing FileThing) Base() string {
+	return filepath.Base(fileThing.Path)
+}
+
+func (fileThing FileThing) Dir() FileThing {
+	return fileThing.withPath(filepath.Dir(fileThing.Path))
+}
+
+func (fileThing FileThing) Ext() string {
+	return filepath.Ext(fileThing.Path)
+}
+
+func (fileThing FileThing) RelativeTo(base FileThing) (string, error) {
+	rel, err := filepath.Rel(base.Path, fileThing.Path)
+	if err != nil {
+		return "", &PathError{Op: "rel", Path: fileThing.Path, Err: err}
+	}
+	return rel, nil
+}
+
+func (fileThing FileThing) Absolute() (FileThing, error) {
+	path, err := fileThing.abs(fileThing.Path)
+	if err != nil {
+		return FileThing{}, &PathError{Op: "abs", Path: fileThing.Path, Err: err}
+	}
+	return fileThing.withPath(path), nil
+}
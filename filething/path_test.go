@@ -0,0 +1,694 @@
+package filething
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var fileThing FileThing
+
+	BeforeEach(func() {
+		fileThing = New(filepath.Join("some", "dir", "file.txt"))
+	})
+
+	Describe("NewValidated", func() {
+		var (
+			path         string
+			validated    FileThing
+			validatedErr error
+		)
+
+		BeforeEach(func() {
+			path = filepath.Join("some", "file")
+		})
+
+		JustBeforeEach(func() {
+			validated, validatedErr = NewValidated(path)
+		})
+
+		It("does not return an error", func() {
+			Expect(validatedErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for the path", func() {
+			Expect(validated.Path).To(Equal(path))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(validated.remove).NotTo(BeNil())
+		})
+
+		Context("when the path is empty", func() {
+			BeforeEach(func() {
+				path = ""
+			})
+
+			It("reports the correct error", func() {
+				Expect(validatedErr).To(MatchError("validate : path is empty"))
+			})
+
+			It("returns a *PathError", func() {
+				var pathErr *PathError
+				Expect(errors.As(validatedErr, &pathErr)).To(BeTrue())
+				Expect(pathErr.Op).To(Equal("validate"))
+			})
+		})
+
+		Context("when the path contains a null byte", func() {
+			BeforeEach(func() {
+				path = "some\x00file"
+			})
+
+			It("returns an error", func() {
+				Expect(validatedErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(validatedErr).To(MatchError(ContainSubstring("path contains a null byte")))
+			})
+
+			It("returns a *PathError", func() {
+				var pathErr *PathError
+				Expect(errors.As(validatedErr, &pathErr)).To(BeTrue())
+				Expect(pathErr.Path).To(Equal(path))
+			})
+		})
+	})
+
+	Describe("NewTemp", func() {
+		var (
+			someDir    string
+			createTemp TempCreator
+			temp       FileThing
+			tempErr    error
+		)
+
+		BeforeEach(func() {
+			someDir = createSomeTempDir()
+			createTemp = ioutil.TempFile
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		JustBeforeEach(func() {
+			temp, tempErr = NewTemp(someDir, "some-*.txt", func(fileThing *FileThing) {
+				fileThing.createTemp = createTemp
+			})
+		})
+
+		It("does not return an error", func() {
+			Expect(tempErr).NotTo(HaveOccurred())
+		})
+
+		It("creates the temp file", func() {
+			Expect(temp.Path).To(BeAnExistingFile())
+		})
+
+		It("creates the temp file in dir using pattern", func() {
+			Expect(filepath.Dir(temp.Path)).To(Equal(someDir))
+			Expect(filepath.Base(temp.Path)).To(MatchRegexp(`^some-\d+\.txt$`))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(temp.Remove()).To(Succeed())
+			Expect(temp.Path).NotTo(BeAnExistingFile())
+		})
+
+		Context("when creating the temp file fails", func() {
+			BeforeEach(func() {
+				createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(tempErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("NewExpanded", func() {
+		var (
+			path        string
+			userHomeDir HomeDirFinder
+			expanded    FileThing
+			expandErr   error
+		)
+
+		BeforeEach(func() {
+			path = "~/sub/file"
+			userHomeDir = func() (string, error) {
+				return filepath.Join("/", "home", "someone"), nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			expanded, expandErr = NewExpanded(path, func(fileThing *FileThing) {
+				fileThing.userHomeDir = userHomeDir
+			})
+		})
+
+		It("does not return an error", func() {
+			Expect(expandErr).NotTo(HaveOccurred())
+		})
+
+		It("expands ~/ to the home directory", func() {
+			Expect(expanded.Path).To(Equal(filepath.Join("/", "home", "someone", "sub", "file")))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(expanded.remove).NotTo(BeNil())
+		})
+
+		Context("when the path is exactly ~", func() {
+			BeforeEach(func() {
+				path = "~"
+			})
+
+			It("expands to the home directory", func() {
+				Expect(expanded.Path).To(Equal(filepath.Join("/", "home", "someone")))
+			})
+		})
+
+		Context("when the path is absolute", func() {
+			BeforeEach(func() {
+				path = filepath.Join("/", "some", "file")
+			})
+
+			It("passes the path through unchanged", func() {
+				Expect(expanded.Path).To(Equal(path))
+			})
+		})
+
+		Context("when the path names another user's home", func() {
+			BeforeEach(func() {
+				path = "~someone/file"
+			})
+
+			It("passes the path through unchanged", func() {
+				Expect(expanded.Path).To(Equal(path))
+			})
+		})
+
+		Context("when looking up the home directory fails", func() {
+			BeforeEach(func() {
+				userHomeDir = func() (string, error) {
+					return "", errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(expandErr).To(MatchError("expand ~/sub/file: I failed"))
+			})
+		})
+	})
+
+	Describe("NewFromEnv", func() {
+		var (
+			path      string
+			lookupEnv EnvLookup
+		)
+
+		withEnv := func(fileThing *FileThing) {
+			fileThing.lookupEnv = lookupEnv
+		}
+
+		BeforeEach(func() {
+			lookupEnv = fakeEnv(map[string]string{"SOME_DIR": "some/dir"})
+		})
+
+		Describe("lenient", func() {
+			var expanded FileThing
+
+			BeforeEach(func() {
+				path = "$SOME_DIR/${SOME_DIR}/file"
+			})
+
+			JustBeforeEach(func() {
+				expanded = NewFromEnv(path, withEnv)
+			})
+
+			It("expands defined variables", func() {
+				Expect(expanded.Path).To(Equal("some/dir/some/dir/file"))
+			})
+
+			It("wires up the default remover", func() {
+				Expect(expanded.remove).NotTo(BeNil())
+			})
+
+			Context("when a variable is undefined", func() {
+				BeforeEach(func() {
+					path = "$UNDEFINED/file"
+				})
+
+				It("expands it to an empty string", func() {
+					Expect(expanded.Path).To(Equal("/file"))
+				})
+			})
+		})
+
+		Describe("strict", func() {
+			var (
+				expanded  FileThing
+				expandErr error
+			)
+
+			BeforeEach(func() {
+				path = "${SOME_DIR}/file"
+			})
+
+			JustBeforeEach(func() {
+				expanded, expandErr = NewFromEnvStrict(path, withEnv)
+			})
+
+			It("does not return an error", func() {
+				Expect(expandErr).NotTo(HaveOccurred())
+			})
+
+			It("expands defined variables", func() {
+				Expect(expanded.Path).To(Equal("some/dir/file"))
+			})
+
+			Context("when a variable is undefined", func() {
+				BeforeEach(func() {
+					path = "$UNDEFINED/file"
+				})
+
+				It("reports the correct error", func() {
+					Expect(expandErr).To(MatchError("expand $UNDEFINED/file: environment variable UNDEFINED is not set"))
+				})
+			})
+
+			Context("when a variable is defined but empty", func() {
+				BeforeEach(func() {
+					lookupEnv = fakeEnv(map[string]string{"EMPTY": ""})
+					path = "${EMPTY}file"
+				})
+
+				It("does not return an error", func() {
+					Expect(expandErr).NotTo(HaveOccurred())
+				})
+
+				It("expands it to an empty string", func() {
+					Expect(expanded.Path).To(Equal("file"))
+				})
+			})
+		})
+	})
+
+	Describe("NewFromURL", func() {
+		var (
+			someDir      string
+			destPath     string
+			response     *http.Response
+			requestedURL string
+			downloaded   FileThing
+			downloadErr  error
+			httpGet      HTTPGetter
+		)
+
+		BeforeEach(func() {
+			someDir = createSomeTempDir()
+			destPath = filepath.Join(someDir, "download")
+			requestedURL = ""
+			response = &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Body:       ioutil.NopCloser(strings.NewReader("some contents")),
+			}
+			httpGet = func(url string) (*http.Response, error) {
+				requestedURL = url
+				return response, nil
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		withHTTPGet := func(fileThing *FileThing) {
+			fileThing.httpGet = httpGet
+		}
+
+		JustBeforeEach(func() {
+			downloaded, downloadErr = NewFromURL("https://example.com/file", destPath, withHTTPGet)
+		})
+
+		It("does not return an error", func() {
+			Expect(downloadErr).NotTo(HaveOccurred())
+		})
+
+		It("requests the url", func() {
+			Expect(requestedURL).To(Equal("https://example.com/file"))
+		})
+
+		It("writes the response body to destPath", func() {
+			Expect(ioutil.ReadFile(destPath)).To(Equal([]byte("some contents")))
+		})
+
+		It("returns a FileThing for destPath", func() {
+			Expect(downloaded.Path).To(Equal(destPath))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(downloaded.Remove()).To(Succeed())
+			Expect(destPath).NotTo(BeAnExistingFile())
+		})
+
+		Context("when the response is not 2xx", func() {
+			BeforeEach(func() {
+				response.StatusCode = http.StatusNotFound
+				response.Status = "404 Not Found"
+			})
+
+			It("reports the correct error", func() {
+				Expect(downloadErr).To(MatchError("download https://example.com/file: unexpected status 404 Not Found"))
+			})
+
+			It("does not create destPath", func() {
+				Expect(destPath).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when reading the body fails part way through", func() {
+			BeforeEach(func() {
+				response.Body = ioutil.NopCloser(io.MultiReader(strings.NewReader("some"), failingReader{}))
+			})
+
+			It("reports the correct error", func() {
+				Expect(downloadErr).To(MatchError("download https://example.com/file: I failed"))
+			})
+
+			It("leaves no partial file", func() {
+				Expect(destPath).NotTo(BeAnExistingFile())
+				Expect(listDir(someDir)).To(BeEmpty())
+			})
+
+			Context("and destPath already exists", func() {
+				BeforeEach(func() {
+					Expect(ioutil.WriteFile(destPath, []byte("some old contents"), 0644)).To(Succeed())
+				})
+
+				It("leaves destPath untouched", func() {
+					Expect(ioutil.ReadFile(destPath)).To(Equal([]byte("some old contents")))
+					Expect(listDir(someDir)).To(ConsistOf("download"))
+				})
+			})
+
+			Context("and destPath is an empty directory", func() {
+				BeforeEach(func() {
+					Expect(os.Mkdir(destPath, 0755)).To(Succeed())
+				})
+
+				It("does not remove it", func() {
+					Expect(destPath).To(BeADirectory())
+				})
+			})
+		})
+
+		It("replaces an existing file at destPath", func() {
+			Expect(ioutil.WriteFile(destPath, []byte("some old contents"), 0644)).To(Succeed())
+			response.Body = ioutil.NopCloser(strings.NewReader("some new contents"))
+
+			_, err := NewFromURL("https://example.com/file", destPath, withHTTPGet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.ReadFile(destPath)).To(Equal([]byte("some new contents")))
+			Expect(listDir(someDir)).To(ConsistOf("download"))
+		})
+
+		It("creates destPath with mode 0644", func() {
+			info, err := os.Stat(destPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0644)))
+		})
+
+		It("downloads beside a destPath with no directory part", func() {
+			var tempDir string
+			_, err := NewFromURL("https://example.com/file", "file.bin", withHTTPGet, func(fileThing *FileThing) {
+				fileThing.createTemp = func(dir, pattern string) (*os.File, error) {
+					tempDir = dir
+					return nil, errors.New("I failed")
+				}
+			})
+			Expect(err).To(MatchError("download https://example.com/file: I failed"))
+			Expect(tempDir).To(Equal("."))
+		})
+
+		Context("when the request fails", func() {
+			BeforeEach(func() {
+				httpGet = func(string) (*http.Response, error) {
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(downloadErr).To(MatchError("download https://example.com/file: I failed"))
+			})
+
+			It("does not create destPath", func() {
+				Expect(destPath).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("NewClean", func() {
+		It("normalizes parent references", func() {
+			Expect(NewClean("./a/../b").Path).To(Equal("b"))
+		})
+
+		It("removes redundant separators and trailing slashes", func() {
+			Expect(NewClean("some//dir/./file/").Path).To(Equal(filepath.Join("some", "dir", "file")))
+		})
+
+		It("leaves an already clean path unchanged", func() {
+			Expect(NewClean(filepath.Join("some", "dir", "file")).Path).To(Equal(filepath.Join("some", "dir", "file")))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(NewClean("some/file").remove).NotTo(BeNil())
+		})
+
+		Context("when the path is real", func() {
+			var someDir string
+
+			BeforeEach(func() {
+				someDir = createSomeTempDir()
+				Expect(ioutil.WriteFile(filepath.Join(someDir, "file"), []byte{}, 0644)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(someDir)
+			})
+
+			It("can be removed", func() {
+				cleaned := NewClean(someDir + "/sub/../file")
+				Expect(cleaned.Remove()).To(Succeed())
+				Expect(filepath.Join(someDir, "file")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("#Base", func() {
+		It("returns the last element of FileThing.Path", func() {
+			Expect(fileThing.Base()).To(Equal("file.txt"))
+		})
+	})
+
+	Describe("#Dir", func() {
+		It("returns a FileThing for the parent directory", func() {
+			Expect(fileThing.Dir().Path).To(Equal(filepath.Join("some", "dir")))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(fileThing.Dir().remove).NotTo(BeNil())
+		})
+
+		It("can be chained", func() {
+			Expect(fileThing.Dir().Dir().Path).To(Equal("some"))
+		})
+
+		Context("when FileThing.Path is a bare filename", func() {
+			BeforeEach(func() {
+				fileThing = New("file.txt")
+			})
+
+			It("returns the current directory", func() {
+				Expect(fileThing.Dir().Path).To(Equal("."))
+			})
+		})
+
+		Context("when the directory is real", func() {
+			var someDir string
+
+			BeforeEach(func() {
+				someDir = createSomeTempDir()
+				fileThing = New(filepath.Join(someDir, "file.txt"))
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(someDir)
+			})
+
+			It("returns a usable FileThing", func() {
+				Expect(fileThing.Dir().Remove()).To(Succeed())
+				Expect(someDir).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("#Ext", func() {
+		It("returns the file extension", func() {
+			Expect(fileThing.Ext()).To(Equal(".txt"))
+		})
+
+		Context("when FileThing.Path has no extension", func() {
+			BeforeEach(func() {
+				fileThing = New(filepath.Join("some", "dir", "file"))
+			})
+
+			It("returns an empty extension", func() {
+				Expect(fileThing.Ext()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("#RelativeTo", func() {
+		var (
+			base   FileThing
+			rel    string
+			relErr error
+		)
+
+		BeforeEach(func() {
+			fileThing = New(filepath.Join("/", "some", "dir", "sub", "file.txt"))
+			base = New(filepath.Join("/", "some", "dir"))
+		})
+
+		JustBeforeEach(func() {
+			rel, relErr = fileThing.RelativeTo(base)
+		})
+
+		It("does not return an error", func() {
+			Expect(relErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the path under base", func() {
+			Expect(rel).To(Equal(filepath.Join("sub", "file.txt")))
+		})
+
+		Context("when FileThing.Path is not under base", func() {
+			BeforeEach(func() {
+				base = New(filepath.Join("/", "other", "dir"))
+			})
+
+			It("walks up out of base", func() {
+				Expect(rel).To(Equal(filepath.Join("..", "..", "some", "dir", "sub", "file.txt")))
+			})
+		})
+
+		Context("when FileThing.Path is base", func() {
+			BeforeEach(func() {
+				base = fileThing
+			})
+
+			It("returns the current directory", func() {
+				Expect(rel).To(Equal("."))
+			})
+		})
+
+		Context("when FileThing.Path can't be made relative to base", func() {
+			BeforeEach(func() {
+				base = New(filepath.Join("some", "dir"))
+			})
+
+			It("returns an error", func() {
+				Expect(relErr).To(HaveOccurred())
+			})
+
+			It("reports which path failed", func() {
+				Expect(relErr).To(MatchError(ContainSubstring("rel " + fileThing.Path + ": ")))
+			})
+		})
+	})
+
+	Describe("#Absolute", func() {
+		var (
+			absolute FileThing
+			absErr   error
+		)
+
+		BeforeEach(func() {
+			fileThing.abs = func(path string) (string, error) {
+				if filepath.IsAbs(path) {
+					return path, nil
+				}
+				return filepath.Join("/", "working", "dir", path), nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			absolute, absErr = fileThing.Absolute()
+		})
+
+		It("does not return an error", func() {
+			Expect(absErr).NotTo(HaveOccurred())
+		})
+
+		It("resolves a relative path against the working directory", func() {
+			Expect(absolute.Path).To(Equal(filepath.Join("/", "working", "dir", "some", "dir", "file.txt")))
+		})
+
+		It("wires up the default remover", func() {
+			Expect(absolute.remove).NotTo(BeNil())
+		})
+
+		Context("when FileThing.Path is already absolute", func() {
+			BeforeEach(func() {
+				fileThing = fileThing.withPath(filepath.Join("/", "some", "file"))
+			})
+
+			It("passes the path through unchanged", func() {
+				Expect(absolute.Path).To(Equal(filepath.Join("/", "some", "file")))
+			})
+		})
+
+		Context("when using the default resolver", func() {
+			BeforeEach(func() {
+				fileThing = New("file.txt")
+			})
+
+			It("returns an absolute path", func() {
+				Expect(filepath.IsAbs(absolute.Path)).To(BeTrue())
+				Expect(filepath.Base(absolute.Path)).To(Equal("file.txt"))
+			})
+		})
+
+		Context("when resolving the path fails", func() {
+			BeforeEach(func() {
+				fileThing.abs = func(string) (string, error) {
+					return "", errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(absErr).To(MatchError("abs " + fileThing.Path + ": I failed"))
+			})
+		})
+	})
+})
+
+func fakeEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
@@ -0,0 +1,122 @@
+package filething
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+func (fileThing FileThing) Read() ([]byte, error) {
+	data, err := fileThing.read(fileThing.Path)
+	if err != nil {
+		return nil, &PathError{Op: "read", Path: fileThing.Path, Err: err}
+	}
+	return data, nil
+}
+
+func (fileThing FileThing) Reader() (io.ReadCloser, error) {
+	return fileThing.open(fileThing.Path)
+}
+
+func (fileThing FileThing) WriteTo(w io.Writer) (int64, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return io.Copy(w, file)
+}
+
+func (fileThing FileThing) ReadRange(offset, length int64) ([]byte, error) {
+	if offset < 0 {
+		return nil, &PathError{Op: "read", Path: fileThing.Path, Err: fmt.Errorf("negative offset %d", offset)}
+	}
+	if length < 0 {
+		return nil, &PathError{Op: "read", Path: fileThing.Path, Err: fmt.Errorf("negative length %d", length)}
+	}
+
+	file, err := fileThing.openSeeker(fileThing.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(io.LimitReader(file, length))
+}
+
+func (fileThing FileThing) ReadInto(buf []byte) (int, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	n, err := io.ReadFull(file, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, nil
+	}
+	if err != nil {
+		return n, err
+	}
+
+	probe, err := file.Read(make([]byte, 1))
+	if probe > 0 {
+		return n, io.ErrShortBuffer
+	}
+	if err != nil && err != io.EOF {
+		return n, err
+	}
+	return n, nil
+}
+
+func (fileThing FileThing) ContentType() (string, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if n == 0 {
+		return "application/octet-stream", nil
+	}
+	return http.DetectContentType(header[:n]), nil
+}
+
+// IsBinary looks at the first 8KB only: any NUL byte, or more than 30% control
+// characters other than common whitespace, means binary. Bytes above 0x7f are
+// treated as text so UTF-8 files are not misreported.
+func (fileThing FileThing) IsBinary() (bool, error) {
+	file, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	chunk := make([]byte, 8*1024)
+	n, err := io.ReadFull(file, chunk)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+
+	control := 0
+	for _, b := range chunk[:n] {
+		switch {
+		case b == 0:
+			return true, nil
+		case b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\b':
+		case b < 0x20 || b == 0x7f:
+			control++
+		}
+	}
+	return control*10 > n*3, nil
+}
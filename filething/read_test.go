@@ -0,0 +1,647 @@
+package filething
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Read", func() {
+		var (
+			data    []byte
+			readErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			data, readErr = fileThing.Read()
+		})
+
+		It("does not return an error", func() {
+			Expect(readErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the file contents", func() {
+			Expect(data).To(Equal([]byte("some contents")))
+		})
+
+		Context("when FileThing.Path is empty", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte{}, 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not return an error", func() {
+				Expect(readErr).NotTo(HaveOccurred())
+			})
+
+			It("returns no data", func() {
+				Expect(data).To(BeEmpty())
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(readErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("returns no data", func() {
+				Expect(data).To(BeNil())
+			})
+		})
+
+		Context("when the reader is stubbed", func() {
+			BeforeEach(func() {
+				fileThing.read = func(string) ([]byte, error) {
+					return []byte("stubbed contents"), nil
+				}
+			})
+
+			It("returns the stubbed contents", func() {
+				Expect(data).To(Equal([]byte("stubbed contents")))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.read = failToRead
+			})
+
+			It("returns an error", func() {
+				Expect(readErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(readErr).To(MatchError("read " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#Reader", func() {
+		var (
+			reader    io.ReadCloser
+			readerErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			reader, readerErr = fileThing.Reader()
+		})
+
+		AfterEach(func() {
+			if reader != nil {
+				reader.Close()
+			}
+		})
+
+		It("does not return an error", func() {
+			Expect(readerErr).NotTo(HaveOccurred())
+		})
+
+		It("streams the file contents", func() {
+			Expect(ioutil.ReadAll(reader)).To(Equal([]byte("some contents")))
+		})
+
+		Context("when the opener is stubbed", func() {
+			var closed bool
+
+			BeforeEach(func() {
+				closed = false
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return &fakeReadCloser{
+						Reader:  strings.NewReader("stubbed contents"),
+						onClose: func() { closed = true },
+					}, nil
+				}
+			})
+
+			It("streams the stubbed contents in chunks", func() {
+				var contents bytes.Buffer
+				chunk := make([]byte, 3)
+				for {
+					n, err := reader.Read(chunk)
+					contents.Write(chunk[:n])
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+				}
+				Expect(contents.String()).To(Equal("stubbed contents"))
+			})
+
+			It("returns a closeable reader", func() {
+				Expect(reader.Close()).To(Succeed())
+				Expect(closed).To(BeTrue())
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(readerErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("does not return a reader", func() {
+				Expect(reader).To(BeNil())
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(readerErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#WriteTo", func() {
+		var (
+			buffer     *bytes.Buffer
+			written    int64
+			writeToErr error
+		)
+
+		BeforeEach(func() {
+			buffer = new(bytes.Buffer)
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			written, writeToErr = fileThing.WriteTo(buffer)
+		})
+
+		It("implements io.WriterTo", func() {
+			var _ io.WriterTo = fileThing
+		})
+
+		It("does not return an error", func() {
+			Expect(writeToErr).NotTo(HaveOccurred())
+		})
+
+		It("writes the file contents", func() {
+			Expect(buffer.String()).To(Equal("some contents"))
+		})
+
+		It("returns the number of bytes written", func() {
+			Expect(written).To(Equal(int64(len("some contents"))))
+		})
+
+		It("closes the opened file", func() {
+			closed := false
+			fileThing.open = func(string) (io.ReadCloser, error) {
+				return &fakeReadCloser{Reader: strings.NewReader("x"), onClose: func() { closed = true }}, nil
+			}
+			_, err := fileThing.WriteTo(new(bytes.Buffer))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(closed).To(BeTrue())
+		})
+
+		Context("when writing fails part way through", func() {
+			var writer *shortWriteCloser
+
+			JustBeforeEach(func() {
+				writer = &shortWriteCloser{limit: 4}
+				written, writeToErr = fileThing.WriteTo(writer)
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeToErr).To(MatchError("I failed"))
+			})
+
+			It("returns the partial count", func() {
+				Expect(written).To(Equal(int64(4)))
+				Expect(writer.buffer.String()).To(Equal("some"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeToErr).To(MatchError("I failed"))
+			})
+
+			It("writes nothing", func() {
+				Expect(written).To(BeZero())
+			})
+		})
+	})
+
+	Describe("#ReadInto", func() {
+		var (
+			buf     []byte
+			n       int
+			readErr error
+		)
+
+		BeforeEach(func() {
+			buf = make([]byte, 8)
+			fileThing.open = openString("some")
+		})
+
+		JustBeforeEach(func() {
+			n, readErr = fileThing.ReadInto(buf)
+		})
+
+		Context("when the file is smaller than the buffer", func() {
+			It("does not return an error", func() {
+				Expect(readErr).NotTo(HaveOccurred())
+			})
+
+			It("returns the number of bytes read", func() {
+				Expect(n).To(Equal(4))
+				Expect(buf[:n]).To(Equal([]byte("some")))
+			})
+		})
+
+		Context("when the file is the same size as the buffer", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("contents")
+			})
+
+			It("does not return an error", func() {
+				Expect(readErr).NotTo(HaveOccurred())
+			})
+
+			It("fills the buffer", func() {
+				Expect(n).To(Equal(8))
+				Expect(buf).To(Equal([]byte("contents")))
+			})
+		})
+
+		Context("when the file is larger than the buffer", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("some contents")
+			})
+
+			It("returns a short buffer error", func() {
+				Expect(readErr).To(Equal(io.ErrShortBuffer))
+			})
+
+			It("fills the buffer", func() {
+				Expect(n).To(Equal(8))
+				Expect(buf).To(Equal([]byte("some con")))
+			})
+		})
+
+		Context("when the file is empty", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("")
+			})
+
+			It("reads nothing", func() {
+				Expect(readErr).NotTo(HaveOccurred())
+				Expect(n).To(BeZero())
+			})
+		})
+
+		Context("when reading the real file", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile)
+				Expect(ioutil.WriteFile(someFile, []byte("some"), 0644)).To(Succeed())
+			})
+
+			It("reads its contents", func() {
+				Expect(buf[:n]).To(Equal([]byte("some")))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(readErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(io.MultiReader(strings.NewReader("so"), failingReader{})), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(readErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#ContentType", func() {
+		var (
+			contentType    string
+			contentTypeErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			contentType, contentTypeErr = fileThing.ContentType()
+		})
+
+		It("does not return an error", func() {
+			Expect(contentTypeErr).NotTo(HaveOccurred())
+		})
+
+		It("detects plain text", func() {
+			Expect(contentType).To(Equal("text/plain; charset=utf-8"))
+		})
+
+		Context("when FileThing.Path has a PNG signature", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 600))
+			})
+
+			It("detects a PNG", func() {
+				Expect(contentType).To(Equal("image/png"))
+			})
+		})
+
+		Context("when FileThing.Path is empty", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("")
+			})
+
+			It("does not return an error", func() {
+				Expect(contentTypeErr).NotTo(HaveOccurred())
+			})
+
+			It("reports application/octet-stream", func() {
+				Expect(contentType).To(Equal("application/octet-stream"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(contentTypeErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#ReadRange", func() {
+		var (
+			offset   int64
+			length   int64
+			data     []byte
+			rangeErr error
+		)
+
+		BeforeEach(func() {
+			offset, length = 5, 4
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			data, rangeErr = fileThing.ReadRange(offset, length)
+		})
+
+		It("does not return an error", func() {
+			Expect(rangeErr).NotTo(HaveOccurred())
+		})
+
+		It("reads length bytes from offset", func() {
+			Expect(data).To(Equal([]byte("cont")))
+		})
+
+		Context("when the opener is stubbed", func() {
+			BeforeEach(func() {
+				fileThing.openSeeker = openSeekableString("0123456789")
+			})
+
+			It("honours the offset", func() {
+				Expect(data).To(Equal([]byte("5678")))
+			})
+
+			Context("and the range runs past EOF", func() {
+				BeforeEach(func() {
+					offset, length = 8, 10
+				})
+
+				It("does not return an error", func() {
+					Expect(rangeErr).NotTo(HaveOccurred())
+				})
+
+				It("returns the available bytes", func() {
+					Expect(data).To(Equal([]byte("89")))
+				})
+			})
+
+			Context("and the offset is past EOF", func() {
+				BeforeEach(func() {
+					offset = 20
+				})
+
+				It("returns no bytes", func() {
+					Expect(rangeErr).NotTo(HaveOccurred())
+					Expect(data).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("when the offset is negative", func() {
+			var openCalled bool
+
+			BeforeEach(func() {
+				offset = -1
+				openCalled = false
+				fileThing.openSeeker = func(string) (io.ReadSeekCloser, error) {
+					openCalled = true
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(rangeErr).To(MatchError("read " + someFile + ": negative offset -1"))
+			})
+
+			It("does not open FileThing.Path", func() {
+				Expect(openCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the length is negative", func() {
+			BeforeEach(func() {
+				length = -1
+			})
+
+			It("reports the correct error", func() {
+				Expect(rangeErr).To(MatchError("read " + someFile + ": negative length -1"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openSeeker = failToOpenSeeker
+			})
+
+			It("reports the correct error", func() {
+				Expect(rangeErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#IsBinary", func() {
+		var (
+			binary    bool
+			binaryErr error
+		)
+
+		BeforeEach(func() {
+			fileThing.open = openString("some text\nwith lines\tand tabs\r\n")
+		})
+
+		JustBeforeEach(func() {
+			binary, binaryErr = fileThing.IsBinary()
+		})
+
+		It("does not return an error", func() {
+			Expect(binaryErr).NotTo(HaveOccurred())
+		})
+
+		It("reports text as not binary", func() {
+			Expect(binary).To(BeFalse())
+		})
+
+		Context("when the contents contain a NUL byte", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("some text\x00more text")
+			})
+
+			It("reports binary", func() {
+				Expect(binary).To(BeTrue())
+			})
+		})
+
+		Context("when the NUL byte is beyond the first 8KB", func() {
+			BeforeEach(func() {
+				fileThing.open = openString(strings.Repeat("a", 8*1024) + "\x00")
+			})
+
+			It("does not see it", func() {
+				Expect(binary).To(BeFalse())
+			})
+		})
+
+		Context("when the contents are mostly control characters", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("\x01\x02\x03\x04abc")
+			})
+
+			It("reports binary", func() {
+				Expect(binary).To(BeTrue())
+			})
+		})
+
+		Context("when the contents are UTF-8 text", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("héllo wörld ✓")
+			})
+
+			It("reports not binary", func() {
+				Expect(binary).To(BeFalse())
+			})
+		})
+
+		Context("when the file is empty", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("")
+			})
+
+			It("reports not binary", func() {
+				Expect(binaryErr).NotTo(HaveOccurred())
+				Expect(binary).To(BeFalse())
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(binaryErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(failingReader{}), nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(binaryErr).To(MatchError("I failed"))
+			})
+		})
+	})
+})
+
+type fakeReadCloser struct {
+	io.Reader
+	onClose func()
+}
+
+func (file *fakeReadCloser) Close() error {
+	file.onClose()
+	return nil
+}
+
+func failToRead(path string) ([]byte, error) {
+	return nil, errors.New("I failed")
+}
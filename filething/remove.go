@@ -0,0 +1,156 @@
+package filething
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+func (fileThing FileThing) RemoveWithBackup(backupPath string) error {
+	if fileThing.dryRun("remove") {
+		return nil
+	}
+	if _, err := fileThing.Copy(backupPath); err != nil {
+		return err
+	}
+	return fileThing.Remove()
+}
+
+func (fileThing FileThing) MustRemove() {
+	if err := fileThing.Remove(); err != nil {
+		panic(fmt.Sprintf("filething: MustRemove(%q): %v", fileThing.Path, err))
+	}
+}
+
+type removeOnce struct {
+	once sync.Once
+	err  error
+}
+
+func (fileThing FileThing) RemoveOnce() error {
+	fileThing.removeOnce.once.Do(func() {
+		fileThing.removeOnce.err = fileThing.Remove()
+	})
+	return fileThing.removeOnce.err
+}
+
+func (fileThing FileThing) RemoveAll() error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("removeall") {
+		return nil
+	}
+	err := fileThing.removeAll(fileThing.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// RemoveEmptyParents never removes stopAt itself, nor anything outside it.
+func (fileThing FileThing) RemoveEmptyParents(stopAt string) error {
+	if fileThing.dryRun("removeparents") {
+		return nil
+	}
+	if err := fileThing.Remove(); err != nil {
+		return err
+	}
+
+	for dir := filepath.Dir(fileThing.Path); isBelow(dir, stopAt); dir = filepath.Dir(dir) {
+		entries, err := fileThing.readDir(dir)
+		if err != nil {
+			return &PathError{Op: "removeparents", Path: dir, Err: err}
+		}
+		if len(entries) > 0 {
+			return nil
+		}
+		if err := fileThing.remove(dir); err != nil {
+			return &PathError{Op: "removeparents", Path: dir, Err: err}
+		}
+	}
+	return nil
+}
+
+func isBelow(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (fileThing FileThing) RemoveWithRetry(attempts int, backoff time.Duration) error {
+	if attempts < 1 {
+		return &PathError{Op: "remove", Path: fileThing.Path, Err: fmt.Errorf("invalid attempts %d", attempts)}
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fileThing.Remove(); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			fileThing.sleep(backoff)
+		}
+	}
+	return err
+}
+
+func (fileThing FileThing) RemoveContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fileThing.Remove()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (fileThing FileThing) SecureRemove() error {
+	if fileThing.dryRun("secureremove") {
+		return nil
+	}
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return err
+	}
+
+	file, err := fileThing.openFile(fileThing.Path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(file, zeros{}, info.Size()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := fileThing.sync(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return fileThing.Remove()
+}
+
+type zeros struct{}
+
+func (zeros) Read(data []byte) (int, error) {
+	for i := range data {
+		data[i] = 0
+	}
+	return len(data), nil
+}
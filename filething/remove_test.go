@@ -0,0 +1,735 @@
+package filething
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#RemoveWithBackup", func() {
+		var (
+			someBackup   string
+			removeCalled bool
+			removeErr    error
+		)
+
+		BeforeEach(func() {
+			someBackup = someFile + ".bak"
+			removeCalled = false
+			fileThing.remove = func(path string) error {
+				removeCalled = true
+				return os.Remove(path)
+			}
+
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.Remove(someBackup)
+		})
+
+		JustBeforeEach(func() {
+			removeErr = fileThing.RemoveWithBackup(someBackup)
+		})
+
+		It("does not return an error", func() {
+			Expect(removeErr).NotTo(HaveOccurred())
+		})
+
+		It("backs up the file", func() {
+			Expect(ioutil.ReadFile(someBackup)).To(Equal([]byte("some contents")))
+		})
+
+		It("removes the file", func() {
+			Expect(someFile).NotTo(BeAnExistingFile())
+		})
+
+		Context("when the backup fails", func() {
+			BeforeEach(func() {
+				fileThing.copy = failToCopy
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("copy " + someFile + ": I failed"))
+			})
+
+			It("does not remove the file", func() {
+				Expect(removeCalled).To(BeFalse())
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when removing the file fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = failToRemove
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("I failed"))
+			})
+
+			It("keeps the backup", func() {
+				Expect(someBackup).To(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("#MustRemove", func() {
+		It("removes the file", func() {
+			Expect(fileThing.MustRemove).NotTo(Panic())
+			Expect(someFile).NotTo(BeAnExistingFile())
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not panic", func() {
+				Expect(fileThing.MustRemove).NotTo(Panic())
+			})
+		})
+
+		Context("when removing FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = failToRemove
+			})
+
+			It("panics with a message naming the path", func() {
+				Expect(fileThing.MustRemove).To(PanicWith(ContainSubstring(someFile)))
+			})
+
+			It("panics with the underlying error", func() {
+				Expect(fileThing.MustRemove).To(PanicWith(ContainSubstring("I failed")))
+			})
+		})
+	})
+
+	Describe("#RemoveOnce", func() {
+		var removeCalls int32
+
+		BeforeEach(func() {
+			removeCalls = 0
+			fileThing.remove = func(path string) error {
+				atomic.AddInt32(&removeCalls, 1)
+				return os.Remove(path)
+			}
+		})
+
+		It("removes the file", func() {
+			Expect(fileThing.RemoveOnce()).To(Succeed())
+			Expect(someFile).NotTo(BeAnExistingFile())
+		})
+
+		It("removes at most once across many goroutines", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					Expect(fileThing.RemoveOnce()).To(Succeed())
+				}()
+			}
+			wg.Wait()
+
+			Expect(atomic.LoadInt32(&removeCalls)).To(Equal(int32(1)))
+		})
+
+		It("shares the guard between copies", func() {
+			copied := fileThing
+			Expect(fileThing.RemoveOnce()).To(Succeed())
+			Expect(copied.RemoveOnce()).To(Succeed())
+			Expect(atomic.LoadInt32(&removeCalls)).To(Equal(int32(1)))
+		})
+
+		It("does not share the guard with derived FileThings", func() {
+			Expect(fileThing.RemoveOnce()).To(Succeed())
+			derived := fileThing.Dir()
+			derived.remove = func(string) error {
+				atomic.AddInt32(&removeCalls, 1)
+				return nil
+			}
+			Expect(derived.RemoveOnce()).To(Succeed())
+			Expect(atomic.LoadInt32(&removeCalls)).To(Equal(int32(2)))
+		})
+
+		Context("when removing FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = func(string) error {
+					atomic.AddInt32(&removeCalls, 1)
+					return errors.New("I failed")
+				}
+			})
+
+			It("gives every caller the same result", func() {
+				Expect(fileThing.RemoveOnce()).To(MatchError("I failed"))
+				Expect(fileThing.RemoveOnce()).To(MatchError("I failed"))
+				Expect(atomic.LoadInt32(&removeCalls)).To(Equal(int32(1)))
+			})
+		})
+	})
+
+	Describe("#RemoveAll", func() {
+		var (
+			someDir      string
+			removeAllErr error
+		)
+
+		BeforeEach(func() {
+			someDir = createSomeTempDir()
+			err := os.MkdirAll(filepath.Join(someDir, "sub"), 0755)
+			Expect(err).NotTo(HaveOccurred())
+			err = ioutil.WriteFile(filepath.Join(someDir, "sub", "file"), []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+			fileThing = New(someDir)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		JustBeforeEach(func() {
+			removeAllErr = fileThing.RemoveAll()
+		})
+
+		It("does not return an error", func() {
+			Expect(removeAllErr).NotTo(HaveOccurred())
+		})
+
+		It("removes the directory tree", func() {
+			Expect(someDir).NotTo(BeAnExistingFile())
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing.removeAll = removeNotExist
+			})
+
+			It("does not return an error", func() {
+				Expect(removeAllErr).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when removal is not permitted", func() {
+			BeforeEach(func() {
+				fileThing.removeAll = removeNotPermitted
+			})
+
+			It("returns the error verbatim", func() {
+				Expect(removeAllErr).To(Equal(&os.PathError{Op: "unlinkat", Path: someDir, Err: os.ErrPermission}))
+			})
+		})
+	})
+
+	Describe("#RemoveEmptyParents", func() {
+		var (
+			stopAt    string
+			contents  map[string]int
+			removed   []string
+			removeErr error
+		)
+
+		BeforeEach(func() {
+			stopAt = filepath.Join("/", "stop")
+			fileThing = New(filepath.Join(stopAt, "a", "b", "c", "file"))
+			contents = map[string]int{}
+			removed = nil
+
+			fileThing.remove = func(path string) error {
+				removed = append(removed, path)
+				return nil
+			}
+			fileThing.readDir = func(dir string) ([]os.DirEntry, error) {
+				entries := []os.DirEntry{}
+				for i := 0; i < contents[dir]; i++ {
+					entries = append(entries, fs.FileInfoToDirEntry(fakeFileInfo{name: "entry"}))
+				}
+				return entries, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			removeErr = fileThing.RemoveEmptyParents(stopAt)
+		})
+
+		It("does not return an error", func() {
+			Expect(removeErr).NotTo(HaveOccurred())
+		})
+
+		It("removes the file and each empty parent up to stopAt", func() {
+			Expect(removed).To(Equal([]string{
+				fileThing.Path,
+				filepath.Join(stopAt, "a", "b", "c"),
+				filepath.Join(stopAt, "a", "b"),
+				filepath.Join(stopAt, "a"),
+			}))
+		})
+
+		It("never removes stopAt", func() {
+			Expect(removed).NotTo(ContainElement(stopAt))
+		})
+
+		Context("when a parent is not empty", func() {
+			BeforeEach(func() {
+				contents[filepath.Join(stopAt, "a", "b")] = 1
+			})
+
+			It("stops at the first non-empty parent", func() {
+				Expect(removed).To(Equal([]string{
+					fileThing.Path,
+					filepath.Join(stopAt, "a", "b", "c"),
+				}))
+			})
+		})
+
+		Context("when FileThing.Path is not below stopAt", func() {
+			BeforeEach(func() {
+				stopAt = filepath.Join("/", "elsewhere")
+			})
+
+			It("only removes the file", func() {
+				Expect(removed).To(Equal([]string{fileThing.Path}))
+			})
+		})
+
+		Context("when removing the file fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = failToRemove
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when reading a parent fails", func() {
+			BeforeEach(func() {
+				fileThing.readDir = func(string) ([]os.DirEntry, error) {
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports which directory failed", func() {
+				Expect(removeErr).To(MatchError("removeparents " + filepath.Join(stopAt, "a", "b", "c") + ": I failed"))
+			})
+		})
+
+		Context("when removing a parent fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = func(path string) error {
+					removed = append(removed, path)
+					if path == filepath.Join(stopAt, "a", "b") {
+						return errors.New("I failed")
+					}
+					return nil
+				}
+			})
+
+			It("reports which directory failed", func() {
+				Expect(removeErr).To(MatchError("removeparents " + filepath.Join(stopAt, "a", "b") + ": I failed"))
+			})
+
+			It("stops ascending", func() {
+				Expect(removed).NotTo(ContainElement(filepath.Join(stopAt, "a")))
+			})
+		})
+
+		Context("when the tree is real", func() {
+			var someDir string
+
+			BeforeEach(func() {
+				someDir = createSomeTempDir()
+				stopAt = someDir
+				Expect(os.MkdirAll(filepath.Join(someDir, "a", "b"), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(someDir, "a", "b", "file"), []byte{}, 0644)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(someDir, "keep"), []byte{}, 0644)).To(Succeed())
+				fileThing = New(filepath.Join(someDir, "a", "b", "file"))
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(someDir)
+			})
+
+			It("removes the empty chain and keeps stopAt", func() {
+				Expect(filepath.Join(someDir, "a")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(someDir, "keep")).To(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("#RemoveWithRetry", func() {
+		var (
+			attempts    int
+			removeCalls int
+			sleeps      []time.Duration
+			removeErr   error
+		)
+
+		BeforeEach(func() {
+			attempts = 3
+			removeCalls = 0
+			sleeps = nil
+			fileThing.sleep = func(duration time.Duration) {
+				sleeps = append(sleeps, duration)
+			}
+		})
+
+		JustBeforeEach(func() {
+			removeErr = fileThing.RemoveWithRetry(attempts, time.Second)
+		})
+
+		It("does not return an error", func() {
+			Expect(removeErr).NotTo(HaveOccurred())
+		})
+
+		It("removes the file", func() {
+			Expect(someFile).NotTo(BeAnExistingFile())
+		})
+
+		It("does not sleep", func() {
+			Expect(sleeps).To(BeEmpty())
+		})
+
+		Context("when removal succeeds on the second attempt", func() {
+			BeforeEach(func() {
+				fileThing.remove = func(path string) error {
+					removeCalls++
+					if removeCalls == 1 {
+						return errors.New("I failed")
+					}
+					return nil
+				}
+			})
+
+			It("does not return an error", func() {
+				Expect(removeErr).NotTo(HaveOccurred())
+			})
+
+			It("stops after the first success", func() {
+				Expect(removeCalls).To(Equal(2))
+			})
+
+			It("backs off between attempts", func() {
+				Expect(sleeps).To(Equal([]time.Duration{time.Second}))
+			})
+		})
+
+		Context("when every attempt fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = func(path string) error {
+					removeCalls++
+					return fmt.Errorf("I failed %d", removeCalls)
+				}
+			})
+
+			It("returns the last error", func() {
+				Expect(removeErr).To(MatchError("I failed 3"))
+			})
+
+			It("tries every attempt", func() {
+				Expect(removeCalls).To(Equal(3))
+			})
+
+			It("does not sleep after the last attempt", func() {
+				Expect(sleeps).To(Equal([]time.Duration{time.Second, time.Second}))
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing.remove = func(path string) error {
+					removeCalls++
+					return removeNotExist(path)
+				}
+			})
+
+			It("does not return an error", func() {
+				Expect(removeErr).NotTo(HaveOccurred())
+			})
+
+			It("does not retry", func() {
+				Expect(removeCalls).To(Equal(1))
+			})
+		})
+
+		Context("when attempts is less than one", func() {
+			BeforeEach(func() {
+				attempts = 0
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("remove " + someFile + ": invalid attempts 0"))
+			})
+
+			It("does not remove the file", func() {
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("#RemoveContext", func() {
+		var (
+			ctx       context.Context
+			cancel    context.CancelFunc
+			removeErr error
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		JustBeforeEach(func() {
+			removeErr = fileThing.RemoveContext(ctx)
+		})
+
+		It("does not return an error", func() {
+			Expect(removeErr).NotTo(HaveOccurred())
+		})
+
+		It("removes the file", func() {
+			Expect(someFile).NotTo(BeAnExistingFile())
+		})
+
+		Context("when the context is cancelled while removing", func() {
+			var (
+				release  chan struct{}
+				returned chan struct{}
+			)
+
+			BeforeEach(func() {
+				release = make(chan struct{})
+				returned = make(chan struct{})
+
+				release, returned := release, returned
+				fileThing.remove = func(path string) error {
+					defer close(returned)
+					cancel()
+					<-release
+					return nil
+				}
+			})
+
+			AfterEach(func() {
+				select {
+				case <-release:
+				default:
+					close(release)
+				}
+				Eventually(returned).Should(BeClosed())
+			})
+
+			It("returns the context's error", func() {
+				Expect(removeErr).To(MatchError(context.Canceled))
+			})
+
+			It("lets the removal goroutine drain once the remover returns", func() {
+				Consistently(returned).ShouldNot(BeClosed())
+				close(release)
+				Eventually(returned).Should(BeClosed())
+			})
+		})
+
+		Context("when the context is already cancelled", func() {
+			var removeCalled bool
+
+			BeforeEach(func() {
+				removeCalled = false
+				fileThing.remove = func(string) error {
+					removeCalled = true
+					return nil
+				}
+				cancel()
+			})
+
+			It("returns the context's error", func() {
+				Expect(removeErr).To(MatchError(context.Canceled))
+			})
+
+			It("does not remove the file", func() {
+				Expect(removeCalled).To(BeFalse())
+			})
+		})
+
+		Context("when removing FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.remove = failToRemove
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#SecureRemove", func() {
+		var (
+			removeCalled bool
+			synced       io.WriteCloser
+			overwritten  *fakeWriteCloser
+			removeErr    error
+		)
+
+		BeforeEach(func() {
+			removeCalled = false
+			synced = nil
+			overwritten = new(fakeWriteCloser)
+			fileThing.remove = func(path string) error {
+				removeCalled = true
+				return os.Remove(path)
+			}
+			fileThing.sync = func(file io.WriteCloser) error {
+				synced = file
+				return nil
+			}
+
+			err := ioutil.WriteFile(someFile, []byte("secret"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			removeErr = fileThing.SecureRemove()
+		})
+
+		It("does not return an error", func() {
+			Expect(removeErr).NotTo(HaveOccurred())
+		})
+
+		It("removes the file", func() {
+			Expect(removeCalled).To(BeTrue())
+			Expect(someFile).NotTo(BeAnExistingFile())
+		})
+
+		Context("when the opener is stubbed", func() {
+			BeforeEach(func() {
+				fileThing.openFile = func(string, int, os.FileMode) (io.WriteCloser, error) {
+					return overwritten, nil
+				}
+			})
+
+			It("overwrites every byte with zeros", func() {
+				Expect(overwritten.Bytes()).To(Equal(make([]byte, len("secret"))))
+			})
+
+			It("closes the file before removing it", func() {
+				Expect(overwritten.closed).To(BeTrue())
+				Expect(removeCalled).To(BeTrue())
+			})
+
+			It("syncs the zeros to disk", func() {
+				Expect(synced).To(BeIdenticalTo(overwritten))
+			})
+		})
+
+		Context("when syncing fails", func() {
+			BeforeEach(func() {
+				fileThing.openFile = func(string, int, os.FileMode) (io.WriteCloser, error) {
+					return overwritten, nil
+				}
+				fileThing.sync = func(io.WriteCloser) error {
+					return errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("I failed"))
+			})
+
+			It("closes the file", func() {
+				Expect(overwritten.closed).To(BeTrue())
+			})
+
+			It("does not remove the file", func() {
+				Expect(removeCalled).To(BeFalse())
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when overwriting fails", func() {
+			BeforeEach(func() {
+				overwritten.writeErr = errors.New("I failed")
+				fileThing.openFile = func(string, int, os.FileMode) (io.WriteCloser, error) {
+					return overwritten, nil
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("I failed"))
+			})
+
+			It("does not remove the file", func() {
+				Expect(removeCalled).To(BeFalse())
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when opening the file for overwriting fails", func() {
+			BeforeEach(func() {
+				fileThing.openFile = failToOpenFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("I failed"))
+			})
+
+			It("does not remove the file", func() {
+				Expect(removeCalled).To(BeFalse())
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(removeErr).To(MatchError("I failed"))
+			})
+
+			It("does not remove the file", func() {
+				Expect(removeCalled).To(BeFalse())
+			})
+		})
+	})
+})
+
+func removeNotExist(path string) error {
+	return &os.PathError{Op: "unlinkat", Path: path, Err: os.ErrNotExist}
+}
+
+func removeNotPermitted(path string) error {
+	return &os.PathError{Op: "unlinkat", Path: path, Err: os.ErrPermission}
+}
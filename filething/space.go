@@ -0,0 +1,9 @@
+package filething
+
+func (fileThing FileThing) AvailableSpace() (uint64, error) {
+	availableBlocks, blockSize, err := fileThing.statfs(fileThing.Path)
+	if err != nil {
+		return 0, &PathError{Op: "statfs", Path: fileThing.Path, Err: err}
+	}
+	return availableBlocks * blockSize, nil
+}
@@ -0,0 +1,11 @@
+//go:build !(darwin || freebsd || linux)
+
+package filething
+
+import "errors"
+
+var errStatfsUnsupported = errors.New("filesystem statistics are not supported on this platform")
+
+func statfs(path string) (uint64, uint64, error) {
+	return 0, 0, errStatfsUnsupported
+}
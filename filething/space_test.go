@@ -0,0 +1,88 @@
+package filething
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#AvailableSpace", func() {
+		var (
+			space    uint64
+			spaceErr error
+		)
+
+		JustBeforeEach(func() {
+			space, spaceErr = fileThing.AvailableSpace()
+		})
+
+		It("does not return an error", func() {
+			Expect(spaceErr).NotTo(HaveOccurred())
+		})
+
+		Context("when the filesystem reports its free blocks", func() {
+			var statfsPath string
+
+			BeforeEach(func() {
+				fileThing.statfs = func(path string) (uint64, uint64, error) {
+					statfsPath = path
+					return 1000, 4096, nil
+				}
+			})
+
+			It("stats the filesystem containing FileThing.Path", func() {
+				Expect(statfsPath).To(Equal(someFile))
+			})
+
+			It("returns the free bytes", func() {
+				Expect(space).To(Equal(uint64(4096000)))
+			})
+		})
+
+		Context("when FileThing.Path does not exist", func() {
+			BeforeEach(func() {
+				os.Remove(someFile)
+			})
+
+			It("returns an error", func() {
+				Expect(spaceErr).To(HaveOccurred())
+			})
+
+			It("reports which path could not be statted", func() {
+				Expect(spaceErr).To(MatchError(ContainSubstring("statfs " + someFile + ": ")))
+			})
+		})
+
+		Context("when statting the filesystem fails", func() {
+			BeforeEach(func() {
+				fileThing.statfs = failToStatfs
+			})
+
+			It("reports the correct error", func() {
+				Expect(spaceErr).To(MatchError("statfs " + someFile + ": I failed"))
+			})
+		})
+	})
+})
+
+func failToStatfs(string) (uint64, uint64, error) {
+	return 0, 0, errors.New("I failed")
+}
@@ -0,0 +1,13 @@
+//go:build darwin || freebsd || linux
+
+package filething
+
+import "syscall"
+
+func statfs(path string) (uint64, uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return uint64(stat.Bavail), uint64(stat.Bsize), nil
+}
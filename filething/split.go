@@ -0,0 +1,76 @@
+package filething
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+func (fileThing FileThing) Split(chunkSize int64, destDir string) ([]FileThing, error) {
+	if chunkSize <= 0 {
+		return nil, &PathError{Op: "split", Path: fileThing.Path, Err: fmt.Errorf("invalid chunk size %d", chunkSize)}
+	}
+	if fileThing.dryRun("split") {
+		return nil, nil
+	}
+
+	source, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	reader := bufio.NewReader(source)
+	var chunks []FileThing
+	for {
+		chunk := fileThing.withPath(filepath.Join(destDir, fmt.Sprintf("%s.%03d", filepath.Base(fileThing.Path), len(chunks))))
+		if err := fileThing.writeChunk(chunk.Path, reader, chunkSize); err != nil {
+			FileThings(append(chunks, chunk)).RemoveAll()
+			return nil, &PathError{Op: "split", Path: fileThing.Path, Err: err}
+		}
+		chunks = append(chunks, chunk)
+
+		if _, err := reader.Peek(1); err == io.EOF {
+			return chunks, nil
+		} else if err != nil {
+			FileThings(chunks).RemoveAll()
+			return nil, &PathError{Op: "split", Path: fileThing.Path, Err: err}
+		}
+	}
+}
+
+func Join(chunks []FileThing, destPath string) (FileThing, error) {
+	if len(chunks) == 0 {
+		return FileThing{}, &PathError{Op: "join", Path: destPath, Err: errors.New("no chunks")}
+	}
+
+	var data bytes.Buffer
+	for i, chunk := range chunks {
+		contents, err := chunk.Read()
+		if err != nil {
+			return FileThing{}, &PathError{Op: "join", Path: destPath, Err: fmt.Errorf("chunk %d: %w", i, err)}
+		}
+		data.Write(contents)
+	}
+
+	joined := chunks[0].withPath(destPath)
+	if err := joined.Write(data.Bytes()); err != nil {
+		return FileThing{}, err
+	}
+	return joined, nil
+}
+
+func (fileThing FileThing) writeChunk(path string, source io.Reader, size int64) error {
+	chunk, err := fileThing.create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(chunk, source, size); err != nil && err != io.EOF {
+		chunk.Close()
+		return err
+	}
+	return chunk.Close()
+}
@@ -0,0 +1,255 @@
+package filething
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someDir   string
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someDir = createSomeTempDir()
+		someFile = filepath.Join(someDir, "file")
+		fileThing = New(someFile)
+
+		err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(someDir)
+		Expect(someDir).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Split", func() {
+		var (
+			chunkSize int64
+			chunks    []FileThing
+			splitErr  error
+		)
+
+		BeforeEach(func() {
+			chunkSize = 5
+		})
+
+		JustBeforeEach(func() {
+			chunks, splitErr = fileThing.Split(chunkSize, someDir)
+		})
+
+		It("does not return an error", func() {
+			Expect(splitErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for each chunk", func() {
+			Expect(paths(chunks)).To(Equal([]string{
+				filepath.Join(someDir, "file.000"),
+				filepath.Join(someDir, "file.001"),
+				filepath.Join(someDir, "file.002"),
+			}))
+		})
+
+		It("writes sequential chunks of at most chunkSize bytes", func() {
+			Expect(ioutil.ReadFile(filepath.Join(someDir, "file.000"))).To(Equal([]byte("some ")))
+			Expect(ioutil.ReadFile(filepath.Join(someDir, "file.001"))).To(Equal([]byte("conte")))
+			Expect(ioutil.ReadFile(filepath.Join(someDir, "file.002"))).To(Equal([]byte("nts")))
+		})
+
+		Context("when the chunks are stubbed", func() {
+			var created map[string]*fakeWriteCloser
+
+			BeforeEach(func() {
+				created = map[string]*fakeWriteCloser{}
+				fileThing.open = openString("0123456789")
+				fileThing.create = func(path string) (io.WriteCloser, error) {
+					created[filepath.Base(path)] = new(fakeWriteCloser)
+					return created[filepath.Base(path)], nil
+				}
+			})
+
+			Context("and FileThing.Path is an exact multiple of chunkSize", func() {
+				It("splits on the chunk boundaries", func() {
+					Expect(created).To(HaveLen(2))
+					Expect(created["file.000"].String()).To(Equal("01234"))
+					Expect(created["file.001"].String()).To(Equal("56789"))
+				})
+
+				It("closes every chunk", func() {
+					Expect(created["file.000"].closed).To(BeTrue())
+					Expect(created["file.001"].closed).To(BeTrue())
+				})
+			})
+
+			Context("and FileThing.Path is smaller than chunkSize", func() {
+				BeforeEach(func() {
+					chunkSize = 100
+				})
+
+				It("writes exactly one chunk", func() {
+					Expect(chunks).To(HaveLen(1))
+					Expect(created["file.000"].String()).To(Equal("0123456789"))
+				})
+			})
+
+			Context("and FileThing.Path is empty", func() {
+				BeforeEach(func() {
+					fileThing.open = openString("")
+				})
+
+				It("writes a single empty chunk", func() {
+					Expect(chunks).To(HaveLen(1))
+					Expect(created["file.000"].String()).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("when chunkSize is not positive", func() {
+			BeforeEach(func() {
+				chunkSize = 0
+			})
+
+			It("reports the correct error", func() {
+				Expect(splitErr).To(MatchError("split " + someFile + ": invalid chunk size 0"))
+			})
+
+			It("does not write any chunks", func() {
+				Expect(listDir(someDir)).To(ConsistOf("file"))
+			})
+		})
+
+		Context("when creating a chunk fails part way through", func() {
+			BeforeEach(func() {
+				fileThing.create = func(path string) (io.WriteCloser, error) {
+					if filepath.Base(path) == "file.001" {
+						return nil, errors.New("I failed")
+					}
+					return create(path)
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(splitErr).To(MatchError("split " + someFile + ": I failed"))
+			})
+
+			It("removes the chunks already written", func() {
+				Expect(listDir(someDir)).To(ConsistOf("file"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(splitErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("Join", func() {
+		var (
+			chunks   []FileThing
+			someDest string
+			written  map[string][]byte
+			joined   FileThing
+			joinErr  error
+		)
+
+		BeforeEach(func() {
+			someDest = filepath.Join(someDir, "joined")
+			written = map[string][]byte{}
+			contents := map[string]string{"a": "some ", "b": "conte", "c": "nts"}
+
+			chunks = nil
+			for _, name := range []string{"a", "b", "c"} {
+				chunk := New(filepath.Join(someDir, name))
+				chunk.read = func(path string) ([]byte, error) {
+					data, ok := contents[filepath.Base(path)]
+					if !ok {
+						return nil, os.ErrNotExist
+					}
+					return []byte(data), nil
+				}
+				chunk.write = func(path string, data []byte, mode os.FileMode) error {
+					written[path] = data
+					return nil
+				}
+				chunks = append(chunks, chunk)
+			}
+		})
+
+		JustBeforeEach(func() {
+			joined, joinErr = Join(chunks, someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(joinErr).NotTo(HaveOccurred())
+		})
+
+		It("concatenates the chunks in order", func() {
+			Expect(written).To(Equal(map[string][]byte{someDest: []byte("some contents")}))
+		})
+
+		It("returns a FileThing for the destination", func() {
+			Expect(joined.Path).To(Equal(someDest))
+		})
+
+		Context("when a chunk is missing", func() {
+			BeforeEach(func() {
+				chunks[1] = chunks[1].withPath(filepath.Join(someDir, "missing"))
+			})
+
+			It("names the chunk that failed", func() {
+				Expect(joinErr).To(MatchError("join " + someDest + ": chunk 1: read " + filepath.Join(someDir, "missing") + ": file does not exist"))
+			})
+
+			It("does not write a truncated output", func() {
+				Expect(written).To(BeEmpty())
+			})
+		})
+
+		Context("when there are no chunks", func() {
+			BeforeEach(func() {
+				chunks = nil
+			})
+
+			It("reports the correct error", func() {
+				Expect(joinErr).To(MatchError("join " + someDest + ": no chunks"))
+			})
+		})
+
+		Context("when writing the destination fails", func() {
+			BeforeEach(func() {
+				chunks[0].write = failToWrite
+			})
+
+			It("reports the correct error", func() {
+				Expect(joinErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when joining the output of Split", func() {
+			BeforeEach(func() {
+				var err error
+				chunks, err = fileThing.Split(3, someDir)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("round trips the original contents", func() {
+				Expect(joinErr).NotTo(HaveOccurred())
+				Expect(ioutil.ReadFile(someDest)).To(Equal([]byte("some contents")))
+			})
+		})
+	})
+})
@@ -0,0 +1,188 @@
+package filething
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+func (fileThing FileThing) Exists() (bool, error) {
+	if fileThing.existCache != nil {
+		return fileThing.existCache.lookup(fileThing.exists)
+	}
+	return fileThing.exists()
+}
+
+func (fileThing FileThing) exists() (bool, error) {
+	_, err := fileThing.stat(fileThing.Path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (fileThing FileThing) Size() (int64, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return 0, &PathError{Op: "size", Path: fileThing.Path, Err: err}
+	}
+	return info.Size(), nil
+}
+
+func (fileThing FileThing) IsEmpty() (bool, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return false, &PathError{Op: "isempty", Path: fileThing.Path, Err: err}
+	}
+	return info.Size() == 0, nil
+}
+
+func (fileThing FileThing) ModTime() (time.Time, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return time.Time{}, &PathError{Op: "modtime", Path: fileThing.Path, Err: err}
+	}
+	return info.ModTime(), nil
+}
+
+func (fileThing FileThing) IsDir() (bool, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return false, &PathError{Op: "isdir", Path: fileThing.Path, Err: err}
+	}
+	return info.IsDir(), nil
+}
+
+func (fileThing FileThing) NewerThan(other FileThing) (bool, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return false, &PathError{Op: "newerthan", Path: fileThing.Path, Err: err}
+	}
+
+	otherInfo, err := fileThing.stat(other.Path)
+	if err != nil {
+		return false, &PathError{Op: "newerthan", Path: other.Path, Err: err}
+	}
+
+	return info.ModTime().After(otherInfo.ModTime()), nil
+}
+
+func (fileThing FileThing) SameFile(other FileThing) (bool, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return false, &PathError{Op: "samefile", Path: fileThing.Path, Err: err}
+	}
+
+	otherInfo, err := fileThing.stat(other.Path)
+	if err != nil {
+		return false, &PathError{Op: "samefile", Path: other.Path, Err: err}
+	}
+
+	return os.SameFile(info, otherInfo), nil
+}
+
+func (fileThing FileThing) Permissions() (os.FileMode, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return 0, &PathError{Op: "permissions", Path: fileThing.Path, Err: err}
+	}
+	return info.Mode().Perm(), nil
+}
+
+func (fileThing FileThing) Info() (os.FileInfo, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if err != nil {
+		return nil, &PathError{Op: "info", Path: fileThing.Path, Err: err}
+	}
+	return info, nil
+}
+
+// WithCachedStat only caches successful results, so a file that doesn't exist
+// yet is seen as soon as it is created.
+func (fileThing FileThing) WithCachedStat() FileThing {
+	stat := fileThing.stat
+	if fileThing.statCache != nil {
+		stat = fileThing.statCache.stat
+	}
+
+	fileThing.statCache = &statCache{stat: stat, results: map[string]os.FileInfo{}}
+	fileThing.stat = fileThing.statCache.Stat
+	return fileThing
+}
+
+// uncachedStat is for callers that poll FileThing.Path for changes, which a
+// cached stat would hide.
+func (fileThing FileThing) uncachedStat(path string) (os.FileInfo, error) {
+	if fileThing.statCache != nil {
+		return fileThing.statCache.stat(path)
+	}
+	return fileThing.stat(path)
+}
+
+func (fileThing FileThing) InvalidateStat() {
+	if fileThing.statCache != nil {
+		fileThing.statCache.invalidate()
+	}
+}
+
+type statCache struct {
+	stat    Stater
+	mutex   sync.Mutex
+	results map[string]os.FileInfo
+}
+
+func (cache *statCache) Stat(path string) (os.FileInfo, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if info, ok := cache.results[path]; ok {
+		return info, nil
+	}
+	info, err := cache.stat(path)
+	if err != nil {
+		return nil, err
+	}
+	cache.results[path] = info
+	return info, nil
+}
+
+func (cache *statCache) invalidate() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.results = map[string]os.FileInfo{}
+}
+
+type existsCache struct {
+	mutex  sync.Mutex
+	cached bool
+	exists bool
+}
+
+func (cache *existsCache) lookup(exists func() (bool, error)) (bool, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.cached {
+		return cache.exists, nil
+	}
+	result, err := exists()
+	if err != nil {
+		return false, err
+	}
+	cache.cached, cache.exists = true, result
+	return result, nil
+}
+
+func (fileThing FileThing) invalidateExists() {
+	if fileThing.existCache == nil {
+		return
+	}
+	fileThing.existCache.mutex.Lock()
+	defer fileThing.existCache.mutex.Unlock()
+
+	fileThing.existCache.cached = false
+}
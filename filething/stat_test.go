@@ -0,0 +1,725 @@
+package filething
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Exists", func() {
+		var (
+			exists    bool
+			existsErr error
+		)
+
+		JustBeforeEach(func() {
+			exists, existsErr = fileThing.Exists()
+		})
+
+		It("does not return an error", func() {
+			Expect(existsErr).NotTo(HaveOccurred())
+		})
+
+		It("reports that the file exists", func() {
+			Expect(exists).To(BeTrue())
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not return an error", func() {
+				Expect(existsErr).NotTo(HaveOccurred())
+			})
+
+			It("reports that the file does not exist", func() {
+				Expect(exists).To(BeFalse())
+			})
+		})
+
+		Context("when stat reports that FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing.stat = statNotExist
+			})
+
+			It("does not return an error", func() {
+				Expect(existsErr).NotTo(HaveOccurred())
+			})
+
+			It("reports that the file does not exist", func() {
+				Expect(exists).To(BeFalse())
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("returns an error", func() {
+				Expect(existsErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(existsErr).To(MatchError("I failed"))
+			})
+
+			It("reports that the file does not exist", func() {
+				Expect(exists).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("#Size", func() {
+		var (
+			size    int64
+			sizeErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			size, sizeErr = fileThing.Size()
+		})
+
+		It("does not return an error", func() {
+			Expect(sizeErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the size of the file", func() {
+			Expect(size).To(Equal(int64(len("some contents"))))
+		})
+
+		Context("when stat is stubbed", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{size: 1234}, nil
+				}
+			})
+
+			It("returns the stubbed size", func() {
+				Expect(size).To(Equal(int64(1234)))
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(sizeErr, os.ErrNotExist)).To(BeTrue())
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("returns an error", func() {
+				Expect(sizeErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(sizeErr).To(MatchError("size " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#IsEmpty", func() {
+		var (
+			empty    bool
+			emptyErr error
+		)
+
+		JustBeforeEach(func() {
+			empty, emptyErr = fileThing.IsEmpty()
+		})
+
+		It("does not return an error", func() {
+			Expect(emptyErr).NotTo(HaveOccurred())
+		})
+
+		It("reports that a new file is empty", func() {
+			Expect(empty).To(BeTrue())
+		})
+
+		Context("when stat reports a zero size", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{size: 0}, nil
+				}
+			})
+
+			It("reports that the file is empty", func() {
+				Expect(empty).To(BeTrue())
+			})
+		})
+
+		Context("when stat reports a nonzero size", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{size: 1234}, nil
+				}
+			})
+
+			It("reports that the file is not empty", func() {
+				Expect(empty).To(BeFalse())
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing.stat = statNotExist
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(emptyErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("does not report the file as empty", func() {
+				Expect(empty).To(BeFalse())
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(emptyErr).To(MatchError("isempty " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#ModTime", func() {
+		var (
+			modTime    time.Time
+			modTimeErr error
+			someTime   time.Time
+		)
+
+		BeforeEach(func() {
+			someTime = time.Date(2017, time.March, 14, 15, 9, 26, 0, time.UTC)
+			fileThing.stat = func(string) (os.FileInfo, error) {
+				return fakeFileInfo{modTime: someTime}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			modTime, modTimeErr = fileThing.ModTime()
+		})
+
+		It("does not return an error", func() {
+			Expect(modTimeErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the modification time reported by stat", func() {
+			Expect(modTime).To(Equal(someTime))
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("returns an error", func() {
+				Expect(modTimeErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(modTimeErr).To(MatchError("modtime " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#IsDir", func() {
+		var (
+			isDir    bool
+			isDirErr error
+		)
+
+		JustBeforeEach(func() {
+			isDir, isDirErr = fileThing.IsDir()
+		})
+
+		It("does not return an error", func() {
+			Expect(isDirErr).NotTo(HaveOccurred())
+		})
+
+		It("reports that a regular file is not a directory", func() {
+			Expect(isDir).To(BeFalse())
+		})
+
+		Context("when stat reports a directory", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{mode: os.ModeDir | 0755}, nil
+				}
+			})
+
+			It("reports a directory", func() {
+				Expect(isDir).To(BeTrue())
+			})
+		})
+
+		Context("when stat reports a regular file", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return fakeFileInfo{mode: 0644}, nil
+				}
+			})
+
+			It("reports that it is not a directory", func() {
+				Expect(isDir).To(BeFalse())
+			})
+		})
+
+		Context("when stat reports that FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing.stat = statNotExist
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(isDirErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("reports the correct error", func() {
+				Expect(isDirErr).To(MatchError(ContainSubstring("isdir " + someFile)))
+			})
+		})
+	})
+
+	Describe("#SameFile", func() {
+		var (
+			other     FileThing
+			otherFile string
+			infos     map[string]os.FileInfo
+			same      bool
+			sameErr   error
+		)
+
+		BeforeEach(func() {
+			otherFile = createSomeTempFile()
+			other = New(filepath.Join("some", "other", "route"))
+
+			someInfo, err := os.Stat(someFile)
+			Expect(err).NotTo(HaveOccurred())
+			infos = map[string]os.FileInfo{
+				someFile:   someInfo,
+				other.Path: someInfo,
+			}
+
+			fileThing.stat = func(path string) (os.FileInfo, error) {
+				info, ok := infos[path]
+				if !ok {
+					return statNotExist(path)
+				}
+				return info, nil
+			}
+		})
+
+		AfterEach(func() {
+			os.Remove(otherFile)
+		})
+
+		JustBeforeEach(func() {
+			same, sameErr = fileThing.SameFile(other)
+		})
+
+		It("does not return an error", func() {
+			Expect(sameErr).NotTo(HaveOccurred())
+		})
+
+		It("reports that both paths are the same file", func() {
+			Expect(same).To(BeTrue())
+		})
+
+		Context("when the paths refer to different files", func() {
+			BeforeEach(func() {
+				otherInfo, err := os.Stat(otherFile)
+				Expect(err).NotTo(HaveOccurred())
+				infos[other.Path] = otherInfo
+			})
+
+			It("reports that they are not the same file", func() {
+				Expect(same).To(BeFalse())
+			})
+		})
+
+		Context("when the other path is a hard link", func() {
+			BeforeEach(func() {
+				fileThing = New(someFile)
+				other = New(someFile + ".link")
+				Expect(os.Link(someFile, other.Path)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				os.Remove(other.Path)
+			})
+
+			It("reports that both paths are the same file", func() {
+				Expect(same).To(BeTrue())
+			})
+		})
+
+		Context("when FileThing.Path is missing", func() {
+			BeforeEach(func() {
+				delete(infos, someFile)
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(sameErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("reports which file is missing", func() {
+				Expect(sameErr).To(MatchError(ContainSubstring("samefile " + someFile + ": ")))
+			})
+		})
+
+		Context("when the other file is missing", func() {
+			BeforeEach(func() {
+				delete(infos, other.Path)
+			})
+
+			It("reports which file is missing", func() {
+				Expect(sameErr).To(MatchError(ContainSubstring("samefile " + other.Path + ": ")))
+			})
+		})
+	})
+
+	Describe("#Permissions", func() {
+		var (
+			permissions    os.FileMode
+			permissionsErr error
+		)
+
+		BeforeEach(func() {
+			fileThing.stat = func(string) (os.FileInfo, error) {
+				return fakeFileInfo{mode: os.ModeDir | os.ModeSetuid | 0750}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			permissions, permissionsErr = fileThing.Permissions()
+		})
+
+		It("does not return an error", func() {
+			Expect(permissionsErr).NotTo(HaveOccurred())
+		})
+
+		It("returns only the permission bits", func() {
+			Expect(permissions).To(Equal(os.FileMode(0750)))
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				fileThing.stat = statNotExist
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(permissionsErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("reports the correct error", func() {
+				Expect(permissionsErr).To(MatchError(ContainSubstring("permissions " + someFile + ": ")))
+			})
+		})
+	})
+
+	Describe("#NewerThan", func() {
+		var (
+			other     FileThing
+			modTimes  map[string]time.Time
+			newer     bool
+			newerErr  error
+			someTime  time.Time
+			otherFile string
+		)
+
+		BeforeEach(func() {
+			someTime = time.Date(2017, time.March, 14, 15, 9, 26, 0, time.UTC)
+			otherFile = someFile + ".other"
+			other = New(otherFile)
+			modTimes = map[string]time.Time{
+				someFile:  someTime,
+				otherFile: someTime.Add(-time.Hour),
+			}
+
+			fileThing.stat = func(path string) (os.FileInfo, error) {
+				modTime, ok := modTimes[path]
+				if !ok {
+					return statNotExist(path)
+				}
+				return fakeFileInfo{modTime: modTime}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			newer, newerErr = fileThing.NewerThan(other)
+		})
+
+		It("does not return an error", func() {
+			Expect(newerErr).NotTo(HaveOccurred())
+		})
+
+		It("reports that FileThing.Path is newer", func() {
+			Expect(newer).To(BeTrue())
+		})
+
+		Context("when the other file is newer", func() {
+			BeforeEach(func() {
+				modTimes[otherFile] = someTime.Add(time.Hour)
+			})
+
+			It("reports that FileThing.Path is not newer", func() {
+				Expect(newer).To(BeFalse())
+			})
+		})
+
+		Context("when both files have the same modification time", func() {
+			BeforeEach(func() {
+				modTimes[otherFile] = someTime
+			})
+
+			It("reports that FileThing.Path is not newer", func() {
+				Expect(newer).To(BeFalse())
+			})
+		})
+
+		Context("when FileThing.Path is missing", func() {
+			BeforeEach(func() {
+				delete(modTimes, someFile)
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(newerErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("reports which file is missing", func() {
+				Expect(newerErr).To(MatchError(ContainSubstring("newerthan " + someFile + ": ")))
+			})
+		})
+
+		Context("when the other file is missing", func() {
+			BeforeEach(func() {
+				delete(modTimes, otherFile)
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(newerErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("reports which file is missing", func() {
+				Expect(newerErr).To(MatchError(ContainSubstring("newerthan " + otherFile + ": ")))
+			})
+		})
+	})
+
+	Describe("#Info", func() {
+		var (
+			info    os.FileInfo
+			infoErr error
+		)
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			info, infoErr = fileThing.Info()
+		})
+
+		It("does not return an error", func() {
+			Expect(infoErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the file's info", func() {
+			Expect(info.Name()).To(Equal(filepath.Base(someFile)))
+			Expect(info.Size()).To(Equal(int64(len("some contents"))))
+		})
+
+		Context("when stat is stubbed", func() {
+			var stubbed fakeFileInfo
+
+			BeforeEach(func() {
+				stubbed = fakeFileInfo{name: "stubbed", size: 1234, mode: 0600}
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					return stubbed, nil
+				}
+			})
+
+			It("returns the stubbed info unchanged", func() {
+				Expect(info).To(Equal(stubbed))
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(infoErr, os.ErrNotExist)).To(BeTrue())
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(infoErr).To(MatchError("info " + someFile + ": I failed"))
+			})
+		})
+	})
+
+	Describe("#WithCachedStat", func() {
+		var statCalls int
+
+		BeforeEach(func() {
+			statCalls = 0
+			fileThing.stat = func(string) (os.FileInfo, error) {
+				statCalls++
+				return fakeFileInfo{size: 13}, nil
+			}
+		})
+
+		Context("when caching is not enabled", func() {
+			It("stats on every call", func() {
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(statCalls).To(Equal(2))
+			})
+		})
+
+		Context("when caching is enabled", func() {
+			BeforeEach(func() {
+				fileThing = fileThing.WithCachedStat()
+			})
+
+			It("stats only once across repeated queries", func() {
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(fileThing.IsDir()).To(BeFalse())
+				Expect(statCalls).To(Equal(1))
+			})
+
+			It("stats again after InvalidateStat", func() {
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				fileThing.InvalidateStat()
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(statCalls).To(Equal(2))
+			})
+
+			It("shares the cache between copies of the same instance", func() {
+				copied := fileThing
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(copied.Size()).To(Equal(int64(13)))
+				Expect(statCalls).To(Equal(1))
+			})
+
+			It("is safe for concurrent use", func() {
+				var wg sync.WaitGroup
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer GinkgoRecover()
+						defer wg.Done()
+						Expect(fileThing.Size()).To(Equal(int64(13)))
+					}()
+				}
+				wg.Wait()
+				Expect(statCalls).To(Equal(1))
+			})
+
+			It("gives derived FileThings a fresh cache", func() {
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(fileThing.Dir().Size()).To(Equal(int64(13)))
+				Expect(fileThing.Dir().Size()).To(Equal(int64(13)))
+				Expect(statCalls).To(Equal(3))
+			})
+
+			It("does not wrap the cache twice", func() {
+				fileThing = fileThing.WithCachedStat()
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(fileThing.Size()).To(Equal(int64(13)))
+				Expect(statCalls).To(Equal(1))
+			})
+
+			Context("and stat fails", func() {
+				BeforeEach(func() {
+					fileThing = New(someFile)
+					fileThing.stat = func(path string) (os.FileInfo, error) {
+						statCalls++
+						return failToStat(path)
+					}
+					fileThing = fileThing.WithCachedStat()
+				})
+
+				It("does not cache the failure", func() {
+					_, err := fileThing.Size()
+					Expect(err).To(HaveOccurred())
+					_, err = fileThing.Size()
+					Expect(err).To(HaveOccurred())
+					Expect(statCalls).To(Equal(2))
+				})
+			})
+		})
+	})
+})
+
+type fakeFileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (info fakeFileInfo) Name() string       { return info.name }
+func (info fakeFileInfo) Size() int64        { return info.size }
+func (info fakeFileInfo) Mode() os.FileMode  { return info.mode }
+func (info fakeFileInfo) ModTime() time.Time { return info.modTime }
+func (info fakeFileInfo) IsDir() bool        { return info.mode.IsDir() }
+func (info fakeFileInfo) Sys() interface{}   { return nil }
+
+func failToStat(path string) (os.FileInfo, error) {
+	return nil, errors.New("I failed")
+}
+
+func statNotExist(path string) (os.FileInfo, error) {
+	return nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+}
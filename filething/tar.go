@@ -0,0 +1,239 @@
+package filething
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (fileThing FileThing) Tar(destPath string) (FileThing, error) {
+	if fileThing.dryRun("tar") {
+		return fileThing.withPath(destPath), nil
+	}
+	destination, err := fileThing.create(destPath)
+	if err != nil {
+		return FileThing{}, err
+	}
+	archived := fileThing.withPath(destPath)
+
+	writer := tar.NewWriter(destination)
+	err = fileThing.walk(fileThing.Path, func(path string, info os.FileInfo, err error) error {
+		if err == nil && (path == destPath || path == fileThing.Path && info.IsDir()) {
+			return nil
+		}
+		if err == nil {
+			err = fileThing.tarEntry(writer, path, info)
+		}
+		if err != nil {
+			return &PathError{Op: "tar", Path: path, Err: err}
+		}
+		return nil
+	})
+	if err == nil {
+		if err = writer.Close(); err != nil {
+			err = &PathError{Op: "tar", Path: fileThing.Path, Err: err}
+		}
+	}
+	if err != nil {
+		destination.Close()
+		archived.Remove()
+		return FileThing{}, err
+	}
+
+	if err := destination.Close(); err != nil {
+		archived.Remove()
+		return FileThing{}, &PathError{Op: "tar", Path: fileThing.Path, Err: err}
+	}
+	return archived, nil
+}
+
+func (fileThing FileThing) tarEntry(writer *tar.Writer, path string, info os.FileInfo) error {
+	name, err := filepath.Rel(fileThing.Path, path)
+	if err != nil {
+		return err
+	}
+	if name == "." {
+		name = filepath.Base(path)
+	}
+
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = fileThing.readlink(path); err != nil {
+			return err
+		}
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(name)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := writer.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := fileThing.open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+func (fileThing FileThing) Untar(destDir string) error {
+	if fileThing.dryRun("untar") {
+		return nil
+	}
+	source, err := fileThing.open(fileThing.Path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	reader := tar.NewReader(source)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &PathError{Op: "untar", Path: fileThing.Path, Err: err}
+		}
+
+		target, err := archivePath(destDir, header.Name)
+		if err != nil {
+			return &PathError{Op: "untar", Path: fileThing.Path, Err: err}
+		}
+		if err := checkInside(destDir, target); err != nil {
+			return &PathError{Op: "untar", Path: fileThing.Path, Err: err}
+		}
+		if err := fileThing.untarEntry(reader, header, destDir, target); err != nil {
+			return &PathError{Op: "untar", Path: target, Err: err}
+		}
+	}
+}
+
+func (fileThing FileThing) untarEntry(reader io.Reader, header *tar.Header, destDir, target string) error {
+	mode := header.FileInfo().Mode()
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return fileThing.mkdirAll(target, mode.Perm())
+	case tar.TypeReg:
+		if err := fileThing.mkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		file, err := fileThing.openFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, reader); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	case tar.TypeSymlink:
+		if err := checkLinkTarget(header.Name, header.Linkname); err != nil {
+			return err
+		}
+		if err := checkLinkInside(destDir, target, header.Linkname); err != nil {
+			return err
+		}
+		if err := fileThing.mkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return fileThing.symlink(header.Linkname, target)
+	default:
+		return fmt.Errorf("unsupported entry type %q in archive", header.Typeflag)
+	}
+}
+
+func archivePath(destDir, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("illegal path %q in archive: absolute path", name)
+	}
+	for _, part := range strings.Split(filepath.ToSlash(name), "/") {
+		if part == ".." {
+			return "", fmt.Errorf("illegal path %q in archive: path traversal", name)
+		}
+	}
+	return filepath.Join(destDir, filepath.FromSlash(name)), nil
+}
+
+func checkLinkTarget(name, target string) error {
+	if _, err := archivePath("", filepath.Join(filepath.Dir(name), target)); err != nil || filepath.IsAbs(target) {
+		return fmt.Errorf("illegal link target %q in archive", target)
+	}
+	return nil
+}
+
+// checkInside resolves the symlinks already extracted into destDir, so that a
+// chain of links that each look harmless can't lead a later entry outside it.
+func checkInside(destDir, path string) error {
+	root, err := resolveExisting(destDir)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveExisting(path)
+	if err != nil {
+		return err
+	}
+	if resolved != root && !isBelow(resolved, root) {
+		return fmt.Errorf("illegal path %q in archive: escapes destination through a symlink", path)
+	}
+	return nil
+}
+
+func checkLinkInside(destDir, path, target string) error {
+	resolved, err := resolveExisting(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		switch part {
+		case "", ".":
+		case "..":
+			resolved = filepath.Dir(resolved)
+		default:
+			if resolved, err = resolveExisting(filepath.Join(resolved, part)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := checkInside(destDir, resolved); err != nil {
+		return fmt.Errorf("illegal link target %q in archive", target)
+	}
+	return nil
+}
+
+// resolveExisting evaluates symlinks in the longest existing prefix of path
+// and appends the rest unchanged.
+func resolveExisting(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return filepath.Abs(resolved)
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return filepath.Abs(path)
+	}
+	resolvedParent, err := resolveExisting(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(path)), nil
+}
@@ -0,0 +1,389 @@
+package filething
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someDir   string
+		someSrc   string
+		someDest  string
+	)
+
+	BeforeEach(func() {
+		someDir = createSomeTempDir()
+		someSrc = filepath.Join(someDir, "src")
+		someDest = filepath.Join(someDir, "archive.tar")
+		fileThing = New(someSrc)
+
+		Expect(os.MkdirAll(filepath.Join(someSrc, "sub"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(someSrc, "file"), []byte("some contents"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(someSrc, "sub", "other"), []byte("other"), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(someDir)
+		Expect(someDir).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Tar", func() {
+		var (
+			archived FileThing
+			tarErr   error
+		)
+
+		JustBeforeEach(func() {
+			archived, tarErr = fileThing.Tar(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(tarErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for the archive", func() {
+			Expect(archived.Path).To(Equal(someDest))
+		})
+
+		It("archives the tree with paths relative to FileThing.Path", func() {
+			data, err := ioutil.ReadFile(someDest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarEntries(data)).To(Equal([]tarEntry{
+				{name: "file", size: 13, contents: "some contents"},
+				{name: "sub/"},
+				{name: "sub/other", size: 5, contents: "other"},
+			}))
+		})
+
+		Context("when the tree is synthetic", func() {
+			var (
+				archive  *fakeWriteCloser
+				contents map[string]string
+			)
+
+			BeforeEach(func() {
+				archive = new(fakeWriteCloser)
+				contents = map[string]string{
+					filepath.Join(someSrc, "a"):        "aaa",
+					filepath.Join(someSrc, "nested/b"): "bb",
+				}
+
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return archive, nil
+				}
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					entries := []struct {
+						path string
+						info fakeFileInfo
+					}{
+						{root, fakeFileInfo{name: "src", mode: os.ModeDir | 0755}},
+						{filepath.Join(root, "a"), fakeFileInfo{name: "a", size: 3, mode: 0644}},
+						{filepath.Join(root, "nested"), fakeFileInfo{name: "nested", mode: os.ModeDir | 0755}},
+						{filepath.Join(root, "nested/b"), fakeFileInfo{name: "b", size: 2, mode: 0600}},
+					}
+					for _, entry := range entries {
+						if err := walkFn(entry.path, entry.info, nil); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+				fileThing.open = func(path string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader(contents[path])), nil
+				}
+			})
+
+			It("writes an entry for each file and directory", func() {
+				Expect(tarEntries(archive.Bytes())).To(Equal([]tarEntry{
+					{name: "a", size: 3, contents: "aaa"},
+					{name: "nested/"},
+					{name: "nested/b", size: 2, contents: "bb"},
+				}))
+			})
+
+			It("closes the archive", func() {
+				Expect(archive.closed).To(BeTrue())
+			})
+
+			Context("and reading a file fails", func() {
+				var removedPath string
+
+				BeforeEach(func() {
+					removedPath = ""
+					fileThing.remove = func(path string) error {
+						removedPath = path
+						return nil
+					}
+					fileThing.open = func(path string) (io.ReadCloser, error) {
+						if strings.HasSuffix(path, "b") {
+							return ioutil.NopCloser(failingReader{}), nil
+						}
+						return ioutil.NopCloser(strings.NewReader(contents[path])), nil
+					}
+				})
+
+				It("reports the offending path", func() {
+					Expect(tarErr).To(MatchError("tar " + filepath.Join(someSrc, "nested/b") + ": I failed"))
+				})
+
+				It("removes the partial archive", func() {
+					Expect(removedPath).To(Equal(someDest))
+				})
+			})
+
+			Context("and closing the archive fails", func() {
+				BeforeEach(func() {
+					archive.closeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(tarErr).To(MatchError("tar " + someSrc + ": I failed"))
+				})
+			})
+		})
+
+		Context("when the walker reports an error", func() {
+			BeforeEach(func() {
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					return walkFn(root, nil, errors.New("I failed"))
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(tarErr).To(MatchError("tar " + someSrc + ": I failed"))
+			})
+
+			It("removes the partial archive", func() {
+				Expect(someDest).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when creating the archive fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(tarErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Untar", func() {
+		var (
+			destDir  string
+			untarErr error
+		)
+
+		BeforeEach(func() {
+			destDir = filepath.Join(someDir, "dest")
+			_, err := fileThing.Tar(someDest)
+			Expect(err).NotTo(HaveOccurred())
+			fileThing = New(someDest)
+		})
+
+		JustBeforeEach(func() {
+			untarErr = fileThing.Untar(destDir)
+		})
+
+		It("does not return an error", func() {
+			Expect(untarErr).NotTo(HaveOccurred())
+		})
+
+		It("round trips the archived tree", func() {
+			Expect(ioutil.ReadFile(filepath.Join(destDir, "file"))).To(Equal([]byte("some contents")))
+			Expect(ioutil.ReadFile(filepath.Join(destDir, "sub", "other"))).To(Equal([]byte("other")))
+		})
+
+		It("preserves file modes", func() {
+			info, err := os.Stat(filepath.Join(destDir, "sub", "other"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		Context("when the archive is crafted", func() {
+			var (
+				entries  []*tar.Header
+				madeDirs []string
+			)
+
+			BeforeEach(func() {
+				madeDirs = nil
+				entries = []*tar.Header{
+					{Name: "nested/", Typeflag: tar.TypeDir, Mode: 0755},
+					{Name: "nested/file", Typeflag: tar.TypeReg, Mode: 0644, Size: 3},
+				}
+
+				fileThing.open = func(string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(bytes.NewReader(tarArchive(entries))), nil
+				}
+				fileThing.mkdirAll = func(path string, perm os.FileMode) error {
+					madeDirs = append(madeDirs, path)
+					return os.MkdirAll(path, perm)
+				}
+			})
+
+			It("creates directories in the destination", func() {
+				Expect(madeDirs).To(ContainElement(filepath.Join(destDir, "nested")))
+			})
+
+			It("extracts files into the destination", func() {
+				Expect(ioutil.ReadFile(filepath.Join(destDir, "nested", "file"))).To(Equal([]byte("xxx")))
+			})
+
+			Context("and an entry traverses out of the destination", func() {
+				BeforeEach(func() {
+					entries = append(entries, &tar.Header{Name: "nested/../../evil", Typeflag: tar.TypeReg, Mode: 0644, Size: 3})
+				})
+
+				It("rejects the entry", func() {
+					Expect(untarErr).To(MatchError(ContainSubstring(`illegal path "nested/../../evil" in archive: path traversal`)))
+				})
+
+				It("does not write outside the destination", func() {
+					Expect(filepath.Join(someDir, "evil")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			Context("and an entry has an absolute path", func() {
+				BeforeEach(func() {
+					entries = append(entries, &tar.Header{Name: filepath.Join(someDir, "evil"), Typeflag: tar.TypeReg, Mode: 0644, Size: 3})
+				})
+
+				It("rejects the entry", func() {
+					Expect(untarErr).To(MatchError(ContainSubstring("absolute path")))
+				})
+
+				It("does not write outside the destination", func() {
+					Expect(filepath.Join(someDir, "evil")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			Context("and a symlink points out of the destination", func() {
+				BeforeEach(func() {
+					entries = append(entries, &tar.Header{Name: "nested/link", Typeflag: tar.TypeSymlink, Linkname: "../../evil"})
+				})
+
+				It("rejects the entry", func() {
+					Expect(untarErr).To(MatchError(ContainSubstring(`illegal link target "../../evil" in archive`)))
+				})
+
+				It("does not create the link", func() {
+					_, err := os.Lstat(filepath.Join(destDir, "nested", "link"))
+					Expect(os.IsNotExist(err)).To(BeTrue())
+				})
+			})
+
+			Context("and chained symlinks lead out of the destination", func() {
+				BeforeEach(func() {
+					entries = append(entries,
+						&tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+						&tar.Header{Name: "a/b", Typeflag: tar.TypeSymlink, Linkname: ".."},
+						&tar.Header{Name: "b/evil", Typeflag: tar.TypeReg, Mode: 0644, Size: 3},
+					)
+				})
+
+				It("rejects the escaping link", func() {
+					Expect(untarErr).To(MatchError(ContainSubstring(`illegal link target ".." in archive`)))
+				})
+
+				It("does not write outside the destination", func() {
+					Expect(filepath.Join(someDir, "evil")).NotTo(BeAnExistingFile())
+					_, err := os.Lstat(filepath.Join(destDir, "b"))
+					Expect(os.IsNotExist(err)).To(BeTrue())
+				})
+			})
+
+			Context("and an entry would be written through a symlink out of the destination", func() {
+				BeforeEach(func() {
+					Expect(os.MkdirAll(destDir, 0755)).To(Succeed())
+					Expect(os.Symlink(someDir, filepath.Join(destDir, "out"))).To(Succeed())
+					entries = append(entries, &tar.Header{Name: "out/evil", Typeflag: tar.TypeReg, Mode: 0644, Size: 3})
+				})
+
+				It("rejects the entry", func() {
+					Expect(untarErr).To(MatchError(ContainSubstring(`escapes destination through a symlink`)))
+				})
+
+				It("does not write outside the destination", func() {
+					Expect(filepath.Join(someDir, "evil")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			Context("and creating a directory fails", func() {
+				BeforeEach(func() {
+					fileThing.mkdirAll = failToMkdirAll
+				})
+
+				It("reports the offending path", func() {
+					Expect(untarErr).To(MatchError("untar " + filepath.Join(destDir, "nested") + ": I failed"))
+				})
+			})
+		})
+
+		Context("when FileThing.Path is not a tar archive", func() {
+			BeforeEach(func() {
+				fileThing.open = openString("not a tar archive, but long enough to fill a header block " + strings.Repeat("x", 512))
+			})
+
+			It("reports the correct error", func() {
+				Expect(untarErr).To(MatchError(ContainSubstring("untar " + someDest + ": ")))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.open = failToOpen
+			})
+
+			It("reports the correct error", func() {
+				Expect(untarErr).To(MatchError("I failed"))
+			})
+		})
+	})
+})
+
+type tarEntry struct {
+	name     string
+	size     int64
+	contents string
+}
+
+func tarEntries(data []byte) []tarEntry {
+	var entries []tarEntry
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return entries
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+		entries = append(entries, tarEntry{name: header.Name, size: header.Size, contents: string(contents)})
+	}
+}
+
+func tarArchive(headers []*tar.Header) []byte {
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	for _, header := range headers {
+		Expect(writer.WriteHeader(header)).To(Succeed())
+		_, err := writer.Write(bytes.Repeat([]byte("x"), int(header.Size)))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+	return buffer.Bytes()
+}
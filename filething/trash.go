@@ -0,0 +1,82 @@
+//go:build linux
+
+package filething
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (fileThing FileThing) Trash() error {
+	if fileThing.dryRun("trash") {
+		return nil
+	}
+
+	original, err := fileThing.abs(fileThing.Path)
+	if err != nil {
+		return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+	}
+	trashDir, err := fileThing.trashDir()
+	if err != nil {
+		return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+	}
+
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := fileThing.mkdirAll(dir, 0700); err != nil {
+			return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+		}
+	}
+
+	dest, err := fileThing.uniquePath(filesDir, filepath.Base(original))
+	if err != nil {
+		return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+	}
+	info := fileThing.withPath(filepath.Join(infoDir, filepath.Base(dest)+".trashinfo"))
+
+	file, err := fileThing.openFile(info.Path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+	}
+	if _, err := file.Write([]byte(trashInfo(original, fileThing.now()))); err != nil {
+		file.Close()
+		info.Remove()
+		return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+	}
+	if err := file.Close(); err != nil {
+		info.Remove()
+		return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+	}
+
+	if _, err := fileThing.MoveTo(dest); err != nil {
+		info.Remove()
+		return &PathError{Op: "trash", Path: fileThing.Path, Err: err}
+	}
+	return nil
+}
+
+func (fileThing FileThing) trashDir() (string, error) {
+	if dataHome, ok := fileThing.lookupEnv("XDG_DATA_HOME"); ok && dataHome != "" {
+		return filepath.Join(dataHome, "Trash"), nil
+	}
+
+	home, err := fileThing.userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+func trashInfo(original string, deleted time.Time) string {
+	return strings.Join([]string{
+		"[Trash Info]",
+		"Path=" + (&url.URL{Path: original}).EscapedPath(),
+		fmt.Sprintf("DeletionDate=%s", deleted.Format("2006-01-02T15:04:05")),
+		"",
+	}, "\n")
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package filething
+
+import "errors"
+
+var errTrashUnsupported = errors.New("trash is not supported on this platform")
+
+func (fileThing FileThing) Trash() error {
+	return &PathError{Op: "trash", Path: fileThing.Path, Err: errTrashUnsupported}
+}
@@ -0,0 +1,157 @@
+//go:build linux
+
+package filething
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someDir   string
+		someFile  string
+		someHome  string
+	)
+
+	BeforeEach(func() {
+		someDir = createSomeTempDir()
+		someHome = filepath.Join(someDir, "home")
+		someFile = filepath.Join(someDir, "report.txt")
+		fileThing = New(someFile)
+
+		err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		fileThing.userHomeDir = func() (string, error) {
+			return someHome, nil
+		}
+		fileThing.lookupEnv = fakeEnv(map[string]string{})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(someDir)
+		Expect(someDir).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Trash", func() {
+		var (
+			trashDir string
+			trashErr error
+		)
+
+		BeforeEach(func() {
+			trashDir = filepath.Join(someHome, ".local", "share", "Trash")
+		})
+
+		JustBeforeEach(func() {
+			trashErr = fileThing.Trash()
+		})
+
+		It("does not return an error", func() {
+			Expect(trashErr).NotTo(HaveOccurred())
+		})
+
+		It("moves FileThing.Path into the trash", func() {
+			Expect(someFile).NotTo(BeAnExistingFile())
+			Expect(ioutil.ReadFile(filepath.Join(trashDir, "files", "report.txt"))).To(Equal([]byte("some contents")))
+		})
+
+		It("writes the trash metadata", func() {
+			info, err := ioutil.ReadFile(filepath.Join(trashDir, "info", "report.txt.trashinfo"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(info)).To(MatchRegexp(`^\[Trash Info\]\nPath=` + someFile + `\nDeletionDate=\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\n$`))
+		})
+
+		Context("when the name is already taken in the trash", func() {
+			BeforeEach(func() {
+				Expect(os.MkdirAll(filepath.Join(trashDir, "files"), 0700)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(trashDir, "files", "report.txt"), []byte("older"), 0644)).To(Succeed())
+			})
+
+			It("disambiguates the name with a suffix", func() {
+				Expect(ioutil.ReadFile(filepath.Join(trashDir, "files", "report (1).txt"))).To(Equal([]byte("some contents")))
+			})
+
+			It("leaves the existing trashed file alone", func() {
+				Expect(ioutil.ReadFile(filepath.Join(trashDir, "files", "report.txt"))).To(Equal([]byte("older")))
+			})
+
+			It("names the metadata after the disambiguated name", func() {
+				Expect(filepath.Join(trashDir, "info", "report (1).txt.trashinfo")).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when the path needs escaping", func() {
+			BeforeEach(func() {
+				someFile = filepath.Join(someDir, "some report.txt")
+				Expect(os.Rename(fileThing.Path, someFile)).To(Succeed())
+				fileThing = fileThing.withPath(someFile)
+			})
+
+			It("escapes the original path in the metadata", func() {
+				info, err := ioutil.ReadFile(filepath.Join(trashDir, "info", "some report.txt.trashinfo"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(info)).To(ContainSubstring("Path=" + someDir + "/some%20report.txt\n"))
+			})
+		})
+
+		Context("when XDG_DATA_HOME is set", func() {
+			BeforeEach(func() {
+				trashDir = filepath.Join(someDir, "data", "Trash")
+				fileThing.lookupEnv = fakeEnv(map[string]string{"XDG_DATA_HOME": filepath.Join(someDir, "data")})
+			})
+
+			It("uses the trash under XDG_DATA_HOME", func() {
+				Expect(filepath.Join(trashDir, "files", "report.txt")).To(BeAnExistingFile())
+				Expect(filepath.Join(trashDir, "info", "report.txt.trashinfo")).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when moving FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.rename = failToRename
+			})
+
+			It("reports the correct error", func() {
+				Expect(trashErr).To(MatchError("trash " + someFile + ": I failed"))
+			})
+
+			It("removes the metadata", func() {
+				Expect(filepath.Join(trashDir, "info", "report.txt.trashinfo")).NotTo(BeAnExistingFile())
+			})
+
+			It("leaves FileThing.Path in place", func() {
+				Expect(someFile).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when looking up the home directory fails", func() {
+			BeforeEach(func() {
+				fileThing.userHomeDir = func() (string, error) {
+					return "", errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(trashErr).To(MatchError("trash " + someFile + ": I failed"))
+			})
+		})
+
+		Context("when creating the trash directories fails", func() {
+			BeforeEach(func() {
+				fileThing.mkdirAll = failToMkdirAll
+			})
+
+			It("reports the correct error", func() {
+				Expect(trashErr).To(MatchError("trash " + someFile + ": I failed"))
+			})
+		})
+	})
+})
@@ -0,0 +1,177 @@
+package filething
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+type Op int
+
+const (
+	OpWrite Op = iota + 1
+	OpRemove
+	OpRename
+)
+
+type FileEvent struct {
+	Path string
+	Op   Op
+}
+
+type Watcher interface {
+	Events() <-chan FileEvent
+	Close() error
+}
+
+type WatcherCreator func(string) (Watcher, error)
+
+func (fileThing FileThing) Watch(ctx context.Context) (<-chan FileEvent, error) {
+	watcher, err := fileThing.newWatcher(fileThing.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan FileEvent)
+	go func() {
+		defer close(events)
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events():
+				if !ok {
+					return
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// WaitForExists always stats FileThing.Path, bypassing any existence cache, so
+// that it sees the file appear when something else creates it.
+func (fileThing FileThing) WaitForExists(ctx context.Context, interval time.Duration) error {
+	for {
+		exists, err := fileThing.exists()
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-fileThing.after(interval):
+		}
+	}
+}
+
+// WaitUntilStable treats FileThing.Path as stable once its size and
+// modification time have not changed for at least quiet.
+func (fileThing FileThing) WaitUntilStable(ctx context.Context, quiet, poll time.Duration) error {
+	var (
+		last  os.FileInfo
+		since time.Time
+	)
+	for {
+		info, err := fileThing.uncachedStat(fileThing.Path)
+		if err != nil {
+			return &PathError{Op: "waitstable", Path: fileThing.Path, Err: err}
+		}
+
+		now := fileThing.now()
+		if last == nil || info.Size() != last.Size() || !info.ModTime().Equal(last.ModTime()) {
+			last, since = info, now
+		} else if now.Sub(since) >= quiet {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-fileThing.after(poll):
+		}
+	}
+}
+
+const pollInterval = 100 * time.Millisecond
+
+type pollWatcher struct {
+	events    chan FileEvent
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newPollWatcher(path string) (Watcher, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := &pollWatcher{
+		events: make(chan FileEvent),
+		done:   make(chan struct{}),
+	}
+	go watcher.poll(path, info)
+	return watcher, nil
+}
+
+func (watcher *pollWatcher) Events() <-chan FileEvent {
+	return watcher.events
+}
+
+func (watcher *pollWatcher) Close() error {
+	watcher.closeOnce.Do(func() { close(watcher.done) })
+	return nil
+}
+
+func (watcher *pollWatcher) poll(path string, last os.FileInfo) {
+	defer close(watcher.events)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-watcher.done:
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		event := FileEvent{Path: path}
+		switch {
+		case os.IsNotExist(err):
+			event.Op = OpRemove
+		case err != nil:
+			continue
+		case !os.SameFile(last, info):
+			event.Op = OpRename
+		case !info.ModTime().Equal(last.ModTime()) || info.Size() != last.Size():
+			event.Op = OpWrite
+		default:
+			continue
+		}
+
+		select {
+		case watcher.events <- event:
+		case <-watcher.done:
+			return
+		}
+		if event.Op == OpRemove {
+			return
+		}
+		last = info
+	}
+}
@@ -0,0 +1,344 @@
+package filething
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Watch", func() {
+		var (
+			ctx      context.Context
+			cancel   context.CancelFunc
+			events   <-chan FileEvent
+			watchErr error
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		JustBeforeEach(func() {
+			events, watchErr = fileThing.Watch(ctx)
+		})
+
+		It("does not return an error", func() {
+			Expect(watchErr).NotTo(HaveOccurred())
+		})
+
+		It("reports writes", func() {
+			err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(events).Should(Receive(Equal(FileEvent{Path: someFile, Op: OpWrite})))
+		})
+
+		It("reports removal", func() {
+			err := os.Remove(someFile)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(events).Should(Receive(Equal(FileEvent{Path: someFile, Op: OpRemove})))
+		})
+
+		Context("when the watcher is stubbed", func() {
+			var watcher *fakeWatcher
+
+			BeforeEach(func() {
+				watcher = newFakeWatcher()
+				fileThing.newWatcher = func(string) (Watcher, error) {
+					return watcher, nil
+				}
+			})
+
+			It("forwards events from the watcher", func() {
+				watcher.events <- FileEvent{Path: someFile, Op: OpRename}
+				Eventually(events).Should(Receive(Equal(FileEvent{Path: someFile, Op: OpRename})))
+			})
+
+			It("closes the channel promptly when the context is cancelled", func() {
+				cancel()
+				Eventually(events).Should(BeClosed())
+			})
+
+			It("closes the watcher when the context is cancelled", func() {
+				cancel()
+				Eventually(watcher.closed).Should(BeClosed())
+			})
+
+			It("closes the channel when the watcher stops", func() {
+				close(watcher.events)
+				Eventually(events).Should(BeClosed())
+			})
+		})
+
+		Context("when creating the watcher fails", func() {
+			BeforeEach(func() {
+				fileThing.newWatcher = failToCreateWatcher
+			})
+
+			It("returns an error", func() {
+				Expect(watchErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(watchErr).To(MatchError("I failed"))
+			})
+
+			It("does not return a channel", func() {
+				Expect(events).To(BeNil())
+			})
+		})
+	})
+
+	Describe("#WaitForExists", func() {
+		var (
+			ctx       context.Context
+			cancel    context.CancelFunc
+			statCalls int
+			intervals []time.Duration
+			waitErr   error
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			statCalls = 0
+			intervals = nil
+
+			fileThing.stat = func(path string) (os.FileInfo, error) {
+				statCalls++
+				if statCalls <= 2 {
+					return statNotExist(path)
+				}
+				return fakeFileInfo{}, nil
+			}
+			fileThing.after = func(interval time.Duration) <-chan time.Time {
+				intervals = append(intervals, interval)
+				fired := make(chan time.Time, 1)
+				fired <- time.Time{}
+				return fired
+			}
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		JustBeforeEach(func() {
+			waitErr = fileThing.WaitForExists(ctx, time.Second)
+		})
+
+		It("does not return an error", func() {
+			Expect(waitErr).NotTo(HaveOccurred())
+		})
+
+		It("polls until FileThing.Path exists", func() {
+			Expect(statCalls).To(Equal(3))
+		})
+
+		It("waits for the interval between polls", func() {
+			Expect(intervals).To(Equal([]time.Duration{time.Second, time.Second}))
+		})
+
+		Context("when the context is cancelled before FileThing.Path appears", func() {
+			BeforeEach(func() {
+				fileThing.stat = statNotExist
+				fileThing.after = func(time.Duration) <-chan time.Time {
+					cancel()
+					return nil
+				}
+			})
+
+			It("returns the context's error", func() {
+				Expect(waitErr).To(MatchError(context.Canceled))
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(waitErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#WaitUntilStable", func() {
+		var (
+			ctx       context.Context
+			cancel    context.CancelFunc
+			clock     time.Time
+			sizes     []int64
+			statCalls int
+			waitErr   error
+		)
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			clock = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			sizes = []int64{1, 2, 2, 2, 2}
+			statCalls = 0
+
+			fileThing.stat = func(string) (os.FileInfo, error) {
+				size := sizes[len(sizes)-1]
+				if statCalls < len(sizes) {
+					size = sizes[statCalls]
+				}
+				statCalls++
+				return fakeFileInfo{size: size}, nil
+			}
+			fileThing.now = func() time.Time {
+				return clock
+			}
+			fileThing.after = func(interval time.Duration) <-chan time.Time {
+				clock = clock.Add(interval)
+				fired := make(chan time.Time, 1)
+				fired <- clock
+				return fired
+			}
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		JustBeforeEach(func() {
+			waitErr = fileThing.WaitUntilStable(ctx, 2*time.Second, time.Second)
+		})
+
+		It("does not return an error", func() {
+			Expect(waitErr).NotTo(HaveOccurred())
+		})
+
+		It("returns once the size has been unchanged for the quiet period", func() {
+			Expect(statCalls).To(Equal(4))
+		})
+
+		Context("when the modification time changes", func() {
+			BeforeEach(func() {
+				modTimes := []time.Time{clock, clock, clock.Add(time.Second), clock.Add(time.Second), clock.Add(time.Second)}
+				sizes = []int64{2}
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					modTime := modTimes[len(modTimes)-1]
+					if statCalls < len(modTimes) {
+						modTime = modTimes[statCalls]
+					}
+					statCalls++
+					return fakeFileInfo{size: 2, modTime: modTime}, nil
+				}
+			})
+
+			It("restarts the quiet period", func() {
+				Expect(statCalls).To(Equal(5))
+			})
+		})
+
+		Context("when the file keeps growing", func() {
+			BeforeEach(func() {
+				fileThing.stat = func(string) (os.FileInfo, error) {
+					statCalls++
+					return fakeFileInfo{size: int64(statCalls)}, nil
+				}
+				after := fileThing.after
+				fileThing.after = func(interval time.Duration) <-chan time.Time {
+					if statCalls == 10 {
+						cancel()
+						return nil
+					}
+					return after(interval)
+				}
+			})
+
+			It("never reports stable", func() {
+				Expect(statCalls).To(Equal(10))
+			})
+
+			It("returns the context's error", func() {
+				Expect(waitErr).To(MatchError(context.Canceled))
+			})
+
+			Context("and stat results are cached", func() {
+				BeforeEach(func() {
+					fileThing = fileThing.WithCachedStat()
+				})
+
+				It("still sees the file growing", func() {
+					Expect(statCalls).To(Equal(10))
+					Expect(waitErr).To(MatchError(context.Canceled))
+				})
+			})
+		})
+
+		Context("when the context is cancelled mid-wait", func() {
+			BeforeEach(func() {
+				fileThing.after = func(time.Duration) <-chan time.Time {
+					cancel()
+					return nil
+				}
+			})
+
+			It("returns the context's error", func() {
+				Expect(waitErr).To(MatchError(context.Canceled))
+			})
+		})
+
+		Context("when stat fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(waitErr).To(MatchError("waitstable " + fileThing.Path + ": I failed"))
+			})
+		})
+	})
+})
+
+type fakeWatcher struct {
+	events chan FileEvent
+	closed chan struct{}
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{
+		events: make(chan FileEvent),
+		closed: make(chan struct{}),
+	}
+}
+
+func (watcher *fakeWatcher) Events() <-chan FileEvent {
+	return watcher.events
+}
+
+func (watcher *fakeWatcher) Close() error {
+	close(watcher.closed)
+	return nil
+}
+
+func failToCreateWatcher(path string) (Watcher, error) {
+	return nil, errors.New("I failed")
+}
@@ -0,0 +1,224 @@
+package filething
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (fileThing FileThing) Write(data []byte) error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("write") {
+		return nil
+	}
+	err := fileThing.write(fileThing.Path, data, 0644)
+	if os.IsNotExist(err) {
+		return &PathError{Op: "write", Path: fileThing.Path, Err: fmt.Errorf("directory %s does not exist: %w", filepath.Dir(fileThing.Path), err)}
+	}
+	return err
+}
+
+func (fileThing FileThing) Append(data []byte) error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("append") {
+		return nil
+	}
+	file, err := fileThing.openFile(fileThing.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (fileThing FileThing) AppendLine(line string) error {
+	if fileThing.dryRun("append") {
+		return nil
+	}
+	needsSeparator, err := fileThing.missingTrailingNewline()
+	if err != nil {
+		return err
+	}
+
+	line = strings.TrimSuffix(line, "\n") + "\n"
+	if needsSeparator {
+		line = "\n" + line
+	}
+	return fileThing.Append([]byte(line))
+}
+
+func (fileThing FileThing) missingTrailingNewline() (bool, error) {
+	file, err := fileThing.openSeeker(fileThing.Path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil || size == 0 {
+		return false, err
+	}
+	if _, err := file.Seek(-1, io.SeekEnd); err != nil {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err := io.ReadFull(file, last); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+func (fileThing FileThing) ReadFrom(r io.Reader) (int64, error) {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("write") {
+		return 0, nil
+	}
+	file, err := fileThing.openFile(fileThing.Path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(file, r)
+	if err != nil {
+		file.Close()
+		return n, err
+	}
+	return n, file.Close()
+}
+
+func (fileThing FileThing) WriteSynced(data []byte) error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("write") {
+		return nil
+	}
+	file, err := fileThing.openFile(fileThing.Path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := fileThing.sync(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (fileThing FileThing) WriteAtomic(data []byte) error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("write") {
+		return nil
+	}
+	mode, err := fileThing.atomicMode()
+	if err != nil {
+		return err
+	}
+	dir, base := filepath.Dir(fileThing.Path), filepath.Base(fileThing.Path)
+	temp, err := fileThing.createTemp(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	tempThing := fileThing.withPath(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		tempThing.Remove()
+		return err
+	}
+	if err := fileThing.sync(temp); err != nil {
+		temp.Close()
+		tempThing.Remove()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		tempThing.Remove()
+		return err
+	}
+	if err := fileThing.chmod(tempThing.Path, mode); err != nil {
+		tempThing.Remove()
+		return err
+	}
+	if err := fileThing.rename(tempThing.Path, fileThing.Path); err != nil {
+		tempThing.Remove()
+		return err
+	}
+	return nil
+}
+
+// atomicMode is the mode WriteAtomic gives its replacement, so that rewriting a
+// file in place keeps its permissions.
+func (fileThing FileThing) atomicMode() (os.FileMode, error) {
+	info, err := fileThing.stat(fileThing.Path)
+	if os.IsNotExist(err) {
+		return 0644, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode().Perm(), nil
+}
+
+func (fileThing FileThing) Create() error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("create") {
+		return nil
+	}
+	file, err := fileThing.openFile(fileThing.Path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func (fileThing FileThing) CreateOrTruncate() error {
+	defer fileThing.invalidateExists()
+	if fileThing.dryRun("create") {
+		return nil
+	}
+	file, err := fileThing.create(fileThing.Path)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func (fileThing FileThing) ReplaceInFile(old, new string) (int, error) {
+	if old == "" {
+		return 0, &PathError{Op: "replace", Path: fileThing.Path, Err: errors.New("empty search string")}
+	}
+
+	data, err := fileThing.Read()
+	if err != nil {
+		return 0, err
+	}
+
+	count := strings.Count(string(data), old)
+	if count == 0 {
+		return 0, nil
+	}
+	if err := fileThing.WriteAtomic([]byte(strings.ReplaceAll(string(data), old, new))); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (fileThing FileThing) Prepend(data []byte) error {
+	existing, err := fileThing.Read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return fileThing.WriteAtomic(append(append([]byte{}, data...), existing...))
+}
@@ -0,0 +1,1117 @@
+package filething
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someFile  string
+	)
+
+	BeforeEach(func() {
+		someFile = createSomeTempFile()
+		fileThing = New(someFile)
+	})
+
+	AfterEach(func() {
+		os.Remove(someFile)
+		Expect(someFile).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Write", func() {
+		var writeErr error
+
+		JustBeforeEach(func() {
+			writeErr = fileThing.Write([]byte("new"))
+		})
+
+		It("does not return an error", func() {
+			Expect(writeErr).NotTo(HaveOccurred())
+		})
+
+		It("writes the data", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("new")))
+		})
+
+		Context("when FileThing.Path has longer contents", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte("some old contents"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("fully overwrites them", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("new")))
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("creates the file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("new")))
+			})
+		})
+
+		Context("when the directory of FileThing.Path doesn't exist", func() {
+			var missingDir string
+
+			BeforeEach(func() {
+				missingDir = filepath.Join(someFile+".dir", "missing")
+				fileThing = New(filepath.Join(missingDir, "file"))
+			})
+
+			It("returns a not-exist error", func() {
+				Expect(errors.Is(writeErr, os.ErrNotExist)).To(BeTrue())
+			})
+
+			It("reports the missing directory", func() {
+				Expect(writeErr).To(MatchError(ContainSubstring("directory " + missingDir + " does not exist")))
+			})
+		})
+
+		Context("when writing FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.write = failToWrite
+			})
+
+			It("returns an error", func() {
+				Expect(writeErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeErr).To(MatchError("I failed"))
+			})
+		})
+
+		Context("when the writer is stubbed", func() {
+			var writtenMode os.FileMode
+
+			BeforeEach(func() {
+				fileThing.write = func(path string, data []byte, mode os.FileMode) error {
+					writtenMode = mode
+					return nil
+				}
+			})
+
+			It("writes with mode 0644", func() {
+				Expect(writtenMode).To(Equal(os.FileMode(0644)))
+			})
+		})
+	})
+
+	Describe("#Append", func() {
+		var appendErr error
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(someFile, []byte("some "), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			appendErr = fileThing.Append([]byte("contents"))
+		})
+
+		It("does not return an error", func() {
+			Expect(appendErr).NotTo(HaveOccurred())
+		})
+
+		It("appends the data", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+		})
+
+		It("appends in order when called again", func() {
+			Expect(fileThing.Append([]byte(" and more"))).To(Succeed())
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents and more")))
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not return an error", func() {
+				Expect(appendErr).NotTo(HaveOccurred())
+			})
+
+			It("creates the file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("contents")))
+			})
+		})
+
+		Context("when the opener is stubbed", func() {
+			var (
+				file     *fakeWriteCloser
+				openFlag int
+			)
+
+			BeforeEach(func() {
+				file = new(fakeWriteCloser)
+				fileThing.openFile = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+					openFlag = flag
+					return file, nil
+				}
+			})
+
+			It("opens FileThing.Path for appending", func() {
+				Expect(openFlag & os.O_APPEND).NotTo(BeZero())
+				Expect(openFlag & os.O_CREATE).NotTo(BeZero())
+			})
+
+			It("writes to the opened file", func() {
+				Expect(file.String()).To(Equal("contents"))
+			})
+
+			It("closes the opened file", func() {
+				Expect(file.closed).To(BeTrue())
+			})
+
+			Context("and writing fails", func() {
+				BeforeEach(func() {
+					file.writeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(appendErr).To(MatchError("I failed"))
+				})
+
+				It("closes the opened file", func() {
+					Expect(file.closed).To(BeTrue())
+				})
+			})
+
+			Context("and closing fails", func() {
+				BeforeEach(func() {
+					file.closeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(appendErr).To(MatchError("I failed"))
+				})
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openFile = failToOpenFile
+			})
+
+			It("returns an error", func() {
+				Expect(appendErr).To(HaveOccurred())
+			})
+
+			It("reports the correct error", func() {
+				Expect(appendErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#AppendLine", func() {
+		var (
+			line      string
+			appendErr error
+		)
+
+		BeforeEach(func() {
+			line = "three"
+			err := ioutil.WriteFile(someFile, []byte("one\ntwo\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			appendErr = fileThing.AppendLine(line)
+		})
+
+		It("does not return an error", func() {
+			Expect(appendErr).NotTo(HaveOccurred())
+		})
+
+		It("appends the line with a trailing newline", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\n")))
+		})
+
+		Context("when the file does not end with a newline", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte("one\ntwo"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("separates the line with a newline", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\n")))
+			})
+		})
+
+		Context("when the file is empty", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte{}, 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("writes just the line", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("three\n")))
+			})
+		})
+
+		Context("when the file does not exist", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(someFile)).To(Succeed())
+			})
+
+			It("creates the file with the line", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("three\n")))
+			})
+		})
+
+		Context("when the line already ends with a newline", func() {
+			BeforeEach(func() {
+				line = "three\n"
+			})
+
+			It("does not add another", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\nthree\n")))
+			})
+		})
+
+		Context("when the last byte is stubbed", func() {
+			var destination *fakeWriteCloser
+
+			BeforeEach(func() {
+				destination = new(fakeWriteCloser)
+				fileThing.openSeeker = openSeekableString("no newline")
+				fileThing.openFile = func(string, int, os.FileMode) (io.WriteCloser, error) {
+					return destination, nil
+				}
+			})
+
+			It("inspects it to decide on a separator", func() {
+				Expect(destination.String()).To(Equal("\nthree\n"))
+			})
+		})
+
+		Context("when opening FileThing.Path to inspect it fails", func() {
+			BeforeEach(func() {
+				fileThing.openSeeker = failToOpenSeeker
+			})
+
+			It("reports the correct error", func() {
+				Expect(appendErr).To(MatchError("I failed"))
+			})
+
+			It("does not append", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("one\ntwo\n")))
+			})
+		})
+
+		Context("when opening FileThing.Path to append fails", func() {
+			BeforeEach(func() {
+				fileThing.openFile = failToOpenFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(appendErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#ReadFrom", func() {
+		var (
+			reader      io.Reader
+			readCount   int64
+			readFromErr error
+		)
+
+		BeforeEach(func() {
+			reader = strings.NewReader("contents")
+			err := ioutil.WriteFile(someFile, []byte("some old contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			readCount, readFromErr = fileThing.ReadFrom(reader)
+		})
+
+		It("implements io.ReaderFrom", func() {
+			var _ io.ReaderFrom = fileThing
+		})
+
+		It("does not return an error", func() {
+			Expect(readFromErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the number of bytes read", func() {
+			Expect(readCount).To(Equal(int64(len("contents"))))
+		})
+
+		It("replaces the file contents", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("contents")))
+		})
+
+		Context("when the opener is stubbed", func() {
+			var (
+				file     *fakeWriteCloser
+				openFlag int
+			)
+
+			BeforeEach(func() {
+				file = new(fakeWriteCloser)
+				fileThing.openFile = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+					openFlag = flag
+					return file, nil
+				}
+			})
+
+			It("truncates FileThing.Path before writing", func() {
+				Expect(openFlag & os.O_TRUNC).NotTo(BeZero())
+				Expect(openFlag & os.O_CREATE).NotTo(BeZero())
+			})
+
+			It("writes to the opened file", func() {
+				Expect(file.String()).To(Equal("contents"))
+			})
+
+			It("closes the opened file", func() {
+				Expect(file.closed).To(BeTrue())
+			})
+
+			Context("and writing fails", func() {
+				BeforeEach(func() {
+					file.writeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(readFromErr).To(MatchError("I failed"))
+				})
+
+				It("closes the opened file", func() {
+					Expect(file.closed).To(BeTrue())
+				})
+			})
+
+			Context("and reading fails part way through", func() {
+				BeforeEach(func() {
+					reader = io.MultiReader(strings.NewReader("cont"), failingReader{})
+				})
+
+				It("reports the correct error", func() {
+					Expect(readFromErr).To(MatchError("I failed"))
+				})
+
+				It("returns the partial count", func() {
+					Expect(readCount).To(Equal(int64(4)))
+				})
+
+				It("closes the opened file", func() {
+					Expect(file.closed).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openFile = failToOpenFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(readFromErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#WriteSynced", func() {
+		var writeErr error
+
+		JustBeforeEach(func() {
+			writeErr = fileThing.WriteSynced([]byte("some contents"))
+		})
+
+		It("does not return an error", func() {
+			Expect(writeErr).NotTo(HaveOccurred())
+		})
+
+		It("writes the data", func() {
+			Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("some contents")))
+		})
+
+		Context("when the opener and syncer are stubbed", func() {
+			var (
+				file    *fakeWriteCloser
+				calls   []string
+				syncErr error
+			)
+
+			BeforeEach(func() {
+				file = new(fakeWriteCloser)
+				calls = nil
+				syncErr = nil
+
+				fileThing.openFile = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+					return file, nil
+				}
+				fileThing.sync = func(synced io.WriteCloser) error {
+					Expect(synced).To(BeIdenticalTo(file))
+					Expect(file.String()).To(Equal("some contents"))
+					calls = append(calls, "sync")
+					return syncErr
+				}
+			})
+
+			It("syncs the file before closing it", func() {
+				Expect(calls).To(Equal([]string{"sync"}))
+				Expect(file.closed).To(BeTrue())
+			})
+
+			Context("and syncing fails", func() {
+				BeforeEach(func() {
+					syncErr = errors.New("I failed")
+				})
+
+				It("reports the sync error even though closing succeeds", func() {
+					Expect(writeErr).To(MatchError("I failed"))
+				})
+
+				It("closes the opened file", func() {
+					Expect(file.closed).To(BeTrue())
+				})
+			})
+
+			Context("and writing fails", func() {
+				BeforeEach(func() {
+					file.writeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(writeErr).To(MatchError("I failed"))
+				})
+
+				It("does not sync", func() {
+					Expect(calls).To(BeEmpty())
+				})
+			})
+
+			Context("and closing fails", func() {
+				BeforeEach(func() {
+					file.closeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(writeErr).To(MatchError("I failed"))
+				})
+			})
+		})
+
+		Context("when the opened file does not support sync", func() {
+			BeforeEach(func() {
+				fileThing.openFile = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+					return new(fakeWriteCloser), nil
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(writeErr).To(MatchError("file does not support sync"))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openFile = failToOpenFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#WriteAtomic", func() {
+		var (
+			someDir        string
+			writeAtomicErr error
+		)
+
+		BeforeEach(func() {
+			someDir = createSomeTempDir()
+			fileThing = New(filepath.Join(someDir, "file"))
+			err := ioutil.WriteFile(fileThing.Path, []byte("some old contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		JustBeforeEach(func() {
+			writeAtomicErr = fileThing.WriteAtomic([]byte("new"))
+		})
+
+		It("does not return an error", func() {
+			Expect(writeAtomicErr).NotTo(HaveOccurred())
+		})
+
+		It("replaces the file contents", func() {
+			Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("new")))
+		})
+
+		It("leaves no temp file behind", func() {
+			Expect(listDir(someDir)).To(ConsistOf("file"))
+		})
+
+		It("writes a temp file in the same directory", func() {
+			var tempDir string
+			fileThing.createTemp = func(dir, pattern string) (*os.File, error) {
+				tempDir = dir
+				return ioutil.TempFile(dir, pattern)
+			}
+
+			Expect(fileThing.WriteAtomic([]byte("new"))).To(Succeed())
+			Expect(filepath.Clean(tempDir)).To(Equal(someDir))
+		})
+
+		Context("when FileThing.Path has no directory part", func() {
+			var tempDir string
+
+			BeforeEach(func() {
+				tempDir = ""
+				fileThing = New("config.json")
+				fileThing.createTemp = func(dir, pattern string) (*os.File, error) {
+					tempDir = dir
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("writes the temp file in the current directory", func() {
+				Expect(tempDir).To(Equal("."))
+			})
+		})
+
+		Context("when creating the temp file fails", func() {
+			BeforeEach(func() {
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeAtomicErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some old contents")))
+			})
+		})
+
+		It("keeps the permissions of FileThing.Path", func() {
+			Expect(os.Chmod(fileThing.Path, 0600)).To(Succeed())
+
+			Expect(fileThing.WriteAtomic([]byte("new"))).To(Succeed())
+			info, err := os.Stat(fileThing.Path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("syncs the temp file before renaming it", func() {
+			var events []string
+			fileThing.sync = func(file io.WriteCloser) error {
+				events = append(events, "sync")
+				return syncFile(file)
+			}
+			fileThing.rename = func(from, to string) error {
+				events = append(events, "rename")
+				return os.Rename(from, to)
+			}
+
+			Expect(fileThing.WriteAtomic([]byte("new"))).To(Succeed())
+			Expect(events).To(Equal([]string{"sync", "rename"}))
+		})
+
+		Context("when FileThing.Path does not exist", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(fileThing.Path)).To(Succeed())
+			})
+
+			It("creates it with mode 0644", func() {
+				info, err := os.Stat(fileThing.Path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0644)))
+			})
+		})
+
+		Context("when stating FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.stat = failToStat
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeAtomicErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some old contents")))
+			})
+		})
+
+		Context("when syncing the temp file fails", func() {
+			BeforeEach(func() {
+				fileThing.sync = func(io.WriteCloser) error {
+					return errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeAtomicErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some old contents")))
+			})
+
+			It("removes the temp file", func() {
+				Expect(listDir(someDir)).To(ConsistOf("file"))
+			})
+		})
+
+		Context("when writing the temp file fails", func() {
+			BeforeEach(func() {
+				fileThing.createTemp = func(dir, pattern string) (*os.File, error) {
+					temp, err := ioutil.TempFile(dir, pattern)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(temp.Close()).To(Succeed())
+					return os.Open(temp.Name())
+				}
+			})
+
+			It("reports the error", func() {
+				Expect(writeAtomicErr).To(HaveOccurred())
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some old contents")))
+			})
+
+			It("removes the temp file", func() {
+				Expect(listDir(someDir)).To(ConsistOf("file"))
+			})
+		})
+
+		Context("when renaming the temp file fails", func() {
+			var removedPaths []string
+
+			BeforeEach(func() {
+				removedPaths = nil
+				fileThing.rename = failToRename
+				fileThing.remove = func(path string) error {
+					removedPaths = append(removedPaths, path)
+					return os.Remove(path)
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(writeAtomicErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some old contents")))
+			})
+
+			It("removes the temp file using the remover", func() {
+				Expect(removedPaths).To(HaveLen(1))
+				Expect(filepath.Dir(removedPaths[0])).To(Equal(someDir))
+				Expect(listDir(someDir)).To(ConsistOf("file"))
+			})
+		})
+	})
+
+	Describe("#Create", func() {
+		var createErr error
+
+		BeforeEach(func() {
+			err := os.Remove(someFile)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			createErr = fileThing.Create()
+		})
+
+		It("does not return an error", func() {
+			Expect(createErr).NotTo(HaveOccurred())
+		})
+
+		It("creates an empty file", func() {
+			Expect(ioutil.ReadFile(someFile)).To(BeEmpty())
+		})
+
+		Context("when FileThing.Path already exists", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte("precious"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an exists error", func() {
+				Expect(errors.Is(createErr, os.ErrExist)).To(BeTrue())
+			})
+
+			It("does not truncate the file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(Equal([]byte("precious")))
+			})
+		})
+
+		Context("when the opener is stubbed", func() {
+			var (
+				file     *fakeWriteCloser
+				openFlag int
+			)
+
+			BeforeEach(func() {
+				file = new(fakeWriteCloser)
+				fileThing.openFile = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+					openFlag = flag
+					return file, nil
+				}
+			})
+
+			It("opens FileThing.Path exclusively", func() {
+				Expect(openFlag & os.O_CREATE).NotTo(BeZero())
+				Expect(openFlag & os.O_EXCL).NotTo(BeZero())
+			})
+
+			It("closes the created file", func() {
+				Expect(file.closed).To(BeTrue())
+			})
+		})
+
+		Context("when creating FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openFile = failToOpenFile
+			})
+
+			It("reports the correct error", func() {
+				Expect(createErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#CreateOrTruncate", func() {
+		var createErr error
+
+		JustBeforeEach(func() {
+			createErr = fileThing.CreateOrTruncate()
+		})
+
+		It("does not return an error", func() {
+			Expect(createErr).NotTo(HaveOccurred())
+		})
+
+		Context("when FileThing.Path already exists", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(someFile, []byte("some contents"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("does not return an error", func() {
+				Expect(createErr).NotTo(HaveOccurred())
+			})
+
+			It("truncates the file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(BeEmpty())
+			})
+		})
+
+		Context("when FileThing.Path doesn't exist", func() {
+			BeforeEach(func() {
+				err := os.Remove(someFile)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("creates an empty file", func() {
+				Expect(ioutil.ReadFile(someFile)).To(BeEmpty())
+			})
+		})
+
+		Context("when the creator is stubbed", func() {
+			var file *fakeWriteCloser
+
+			BeforeEach(func() {
+				file = new(fakeWriteCloser)
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return file, nil
+				}
+			})
+
+			It("closes the created file", func() {
+				Expect(file.closed).To(BeTrue())
+			})
+
+			Context("and closing fails", func() {
+				BeforeEach(func() {
+					file.closeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(createErr).To(MatchError("I failed"))
+				})
+			})
+		})
+
+		Context("when creating FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(createErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#ReplaceInFile", func() {
+		var (
+			someDir    string
+			old        string
+			replaced   int
+			replaceErr error
+		)
+
+		BeforeEach(func() {
+			old = "old"
+			someDir = createSomeTempDir()
+			fileThing = New(filepath.Join(someDir, "file"))
+			err := ioutil.WriteFile(fileThing.Path, []byte("some old contents, old and older"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		JustBeforeEach(func() {
+			replaced, replaceErr = fileThing.ReplaceInFile(old, "new")
+		})
+
+		It("does not return an error", func() {
+			Expect(replaceErr).NotTo(HaveOccurred())
+		})
+
+		It("returns the number of replacements", func() {
+			Expect(replaced).To(Equal(3))
+		})
+
+		It("replaces every occurrence", func() {
+			Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some new contents, new and newer")))
+		})
+
+		It("leaves no temp file behind", func() {
+			Expect(listDir(someDir)).To(ConsistOf("file"))
+		})
+
+		Context("when the search string does not appear", func() {
+			var writeCalled bool
+
+			BeforeEach(func() {
+				old = "absent"
+				writeCalled = false
+				fileThing.createTemp = func(string, string) (*os.File, error) {
+					writeCalled = true
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("does not return an error", func() {
+				Expect(replaceErr).NotTo(HaveOccurred())
+			})
+
+			It("returns zero", func() {
+				Expect(replaced).To(BeZero())
+			})
+
+			It("does not rewrite the file", func() {
+				Expect(writeCalled).To(BeFalse())
+				Expect(listDir(someDir)).To(ConsistOf("file"))
+			})
+		})
+
+		Context("when the search string is empty", func() {
+			BeforeEach(func() {
+				old = ""
+			})
+
+			It("reports the correct error", func() {
+				Expect(replaceErr).To(MatchError("replace " + fileThing.Path + ": empty search string"))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.read = failToRead
+			})
+
+			It("reports the correct error", func() {
+				Expect(replaceErr).To(MatchError("read " + fileThing.Path + ": I failed"))
+			})
+		})
+
+		Context("when writing fails after a successful read", func() {
+			BeforeEach(func() {
+				fileThing.read = func(string) ([]byte, error) {
+					return []byte("old"), nil
+				}
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(replaceErr).To(MatchError("I failed"))
+			})
+
+			It("reports no replacements", func() {
+				Expect(replaced).To(BeZero())
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some old contents, old and older")))
+			})
+		})
+	})
+
+	Describe("#Prepend", func() {
+		var (
+			someDir    string
+			prependErr error
+		)
+
+		BeforeEach(func() {
+			someDir = createSomeTempDir()
+			fileThing = New(filepath.Join(someDir, "file"))
+			err := ioutil.WriteFile(fileThing.Path, []byte("some contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(someDir)
+		})
+
+		JustBeforeEach(func() {
+			prependErr = fileThing.Prepend([]byte("new "))
+		})
+
+		It("does not return an error", func() {
+			Expect(prependErr).NotTo(HaveOccurred())
+		})
+
+		It("inserts the data before the existing contents", func() {
+			Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("new some contents")))
+		})
+
+		It("leaves no temp file behind", func() {
+			Expect(listDir(someDir)).To(ConsistOf("file"))
+		})
+
+		Context("when FileThing.Path does not exist", func() {
+			BeforeEach(func() {
+				Expect(os.Remove(fileThing.Path)).To(Succeed())
+			})
+
+			It("does not return an error", func() {
+				Expect(prependErr).NotTo(HaveOccurred())
+			})
+
+			It("creates the file with the data", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("new ")))
+			})
+		})
+
+		Context("when reading FileThing.Path fails", func() {
+			var writeCalled bool
+
+			BeforeEach(func() {
+				writeCalled = false
+				fileThing.read = failToRead
+				fileThing.createTemp = func(string, string) (*os.File, error) {
+					writeCalled = true
+					return nil, errors.New("I failed")
+				}
+			})
+
+			It("reports the correct error", func() {
+				Expect(prependErr).To(MatchError("read " + fileThing.Path + ": I failed"))
+			})
+
+			It("does not write", func() {
+				Expect(writeCalled).To(BeFalse())
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some contents")))
+			})
+		})
+
+		Context("when writing fails", func() {
+			BeforeEach(func() {
+				fileThing.createTemp = failToCreateTemp
+			})
+
+			It("reports the correct error", func() {
+				Expect(prependErr).To(MatchError("I failed"))
+			})
+
+			It("leaves FileThing.Path untouched", func() {
+				Expect(ioutil.ReadFile(fileThing.Path)).To(Equal([]byte("some contents")))
+			})
+		})
+	})
+})
+
+type fakeWriteCloser struct {
+	buffer   bytes.Buffer
+	writeErr error
+	closeErr error
+	closed   bool
+}
+
+func (file *fakeWriteCloser) Write(data []byte) (int, error) {
+	if file.writeErr != nil {
+		return 0, file.writeErr
+	}
+	return file.buffer.Write(data)
+}
+
+func (file *fakeWriteCloser) Bytes() []byte {
+	return file.buffer.Bytes()
+}
+
+func (file *fakeWriteCloser) String() string {
+	return file.buffer.String()
+}
+
+func (file *fakeWriteCloser) Close() error {
+	file.closed = true
+	return file.closeErr
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("I failed")
+}
+
+func failToOpenFile(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+	return nil, errors.New("I failed")
+}
+
+func failToCreateTemp(dir, pattern string) (*os.File, error) {
+	return nil, errors.New("I failed")
+}
+
+func failToWrite(path string, data []byte, mode os.FileMode) error {
+	return errors.New("I failed")
+}
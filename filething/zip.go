@@ -0,0 +1,199 @@
+package filething
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (fileThing FileThing) Zip(destPath string) (FileThing, error) {
+	if fileThing.dryRun("zip") {
+		return fileThing.withPath(destPath), nil
+	}
+	destination, err := fileThing.create(destPath)
+	if err != nil {
+		return FileThing{}, err
+	}
+	archived := fileThing.withPath(destPath)
+
+	writer := zip.NewWriter(destination)
+	err = fileThing.walk(fileThing.Path, func(path string, info os.FileInfo, err error) error {
+		if err == nil && (path == destPath || path == fileThing.Path && info.IsDir()) {
+			return nil
+		}
+		if err == nil {
+			err = fileThing.zipEntry(writer, path, info)
+		}
+		if err != nil {
+			return &PathError{Op: "zip", Path: path, Err: err}
+		}
+		return nil
+	})
+	if err == nil {
+		if err = writer.Close(); err != nil {
+			err = &PathError{Op: "zip", Path: fileThing.Path, Err: err}
+		}
+	}
+	if err != nil {
+		destination.Close()
+		archived.Remove()
+		return FileThing{}, err
+	}
+
+	if err := destination.Close(); err != nil {
+		archived.Remove()
+		return FileThing{}, &PathError{Op: "zip", Path: fileThing.Path, Err: err}
+	}
+	return archived, nil
+}
+
+func (fileThing FileThing) zipEntry(writer *zip.Writer, path string, info os.FileInfo) error {
+	name, err := filepath.Rel(fileThing.Path, path)
+	if err != nil {
+		return err
+	}
+	if name == "." {
+		name = filepath.Base(path)
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(name)
+	if info.IsDir() {
+		header.Name += "/"
+	} else {
+		header.Method = zip.Deflate
+	}
+
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := fileThing.readlink(path)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, strings.NewReader(target))
+		return err
+	case info.Mode().IsRegular():
+		file, err := fileThing.open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(entry, file)
+		return err
+	default:
+		return nil
+	}
+}
+
+func (fileThing FileThing) Unzip(destDir string) error {
+	if fileThing.dryRun("unzip") {
+		return nil
+	}
+	source, err := fileThing.openSeeker(fileThing.Path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	size, err := source.Seek(0, io.SeekEnd)
+	if err != nil {
+		return &PathError{Op: "unzip", Path: fileThing.Path, Err: err}
+	}
+	reader, err := zip.NewReader(readerAt(source), size)
+	if err != nil {
+		return &PathError{Op: "unzip", Path: fileThing.Path, Err: err}
+	}
+
+	for _, file := range reader.File {
+		target, err := archivePath(destDir, file.Name)
+		if err != nil {
+			return &PathError{Op: "unzip", Path: fileThing.Path, Err: err}
+		}
+		if err := checkInside(destDir, target); err != nil {
+			return &PathError{Op: "unzip", Path: fileThing.Path, Err: err}
+		}
+		if err := fileThing.unzipEntry(file, destDir, target); err != nil {
+			return &PathError{Op: "unzip", Path: target, Err: err}
+		}
+	}
+	return nil
+}
+
+func (fileThing FileThing) unzipEntry(file *zip.File, destDir, target string) error {
+	mode := file.Mode()
+	if mode.IsDir() {
+		return fileThing.mkdirAll(target, mode.Perm())
+	}
+	if !mode.IsRegular() && mode&os.ModeSymlink == 0 {
+		return fmt.Errorf("unsupported file mode %s in archive", mode)
+	}
+
+	entry, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+
+	if err := fileThing.mkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	if mode&os.ModeSymlink != 0 {
+		linkTarget, err := ioutil.ReadAll(entry)
+		if err != nil {
+			return err
+		}
+		if err := checkLinkTarget(file.Name, string(linkTarget)); err != nil {
+			return err
+		}
+		if err := checkLinkInside(destDir, target, string(linkTarget)); err != nil {
+			return err
+		}
+		return fileThing.symlink(string(linkTarget), target)
+	}
+
+	destination, err := fileThing.openFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destination, entry); err != nil {
+		destination.Close()
+		return err
+	}
+	return destination.Close()
+}
+
+func readerAt(source io.ReadSeeker) io.ReaderAt {
+	if reader, ok := source.(io.ReaderAt); ok {
+		return reader
+	}
+	return seekReaderAt{source}
+}
+
+type seekReaderAt struct {
+	io.ReadSeeker
+}
+
+func (reader seekReaderAt) ReadAt(data []byte, offset int64) (int, error) {
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(reader.ReadSeeker, data)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,372 @@
+package filething
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileThing", func() {
+	var (
+		fileThing FileThing
+		someDir   string
+		someSrc   string
+		someDest  string
+	)
+
+	BeforeEach(func() {
+		someDir = createSomeTempDir()
+		someSrc = filepath.Join(someDir, "src")
+		someDest = filepath.Join(someDir, "archive.zip")
+		fileThing = New(someSrc)
+
+		Expect(os.MkdirAll(filepath.Join(someSrc, "sub"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(someSrc, "file"), []byte("some contents"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(someSrc, "sub", "other"), []byte("other"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(someDir)
+		Expect(someDir).NotTo(BeAnExistingFile())
+	})
+
+	Describe("#Zip", func() {
+		var (
+			archived FileThing
+			zipErr   error
+		)
+
+		JustBeforeEach(func() {
+			archived, zipErr = fileThing.Zip(someDest)
+		})
+
+		It("does not return an error", func() {
+			Expect(zipErr).NotTo(HaveOccurred())
+		})
+
+		It("returns a FileThing for the archive", func() {
+			Expect(archived.Path).To(Equal(someDest))
+		})
+
+		It("archives the tree with paths relative to FileThing.Path", func() {
+			data, err := ioutil.ReadFile(someDest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zipEntries(data)).To(Equal(map[string]string{
+				"file":      "some contents",
+				"sub/":      "",
+				"sub/other": "other",
+			}))
+		})
+
+		Context("when the tree is synthetic", func() {
+			var (
+				archive  *fakeWriteCloser
+				contents map[string]string
+			)
+
+			BeforeEach(func() {
+				archive = new(fakeWriteCloser)
+				contents = map[string]string{
+					filepath.Join(someSrc, "a"):        "aaa",
+					filepath.Join(someSrc, "nested/b"): "bb",
+				}
+
+				fileThing.create = func(string) (io.WriteCloser, error) {
+					return archive, nil
+				}
+				fileThing.walk = func(root string, walkFn filepath.WalkFunc) error {
+					entries := []struct {
+						path string
+						info fakeFileInfo
+					}{
+						{root, fakeFileInfo{name: "src", mode: os.ModeDir | 0755}},
+						{filepath.Join(root, "a"), fakeFileInfo{name: "a", size: 3, mode: 0644}},
+						{filepath.Join(root, "nested"), fakeFileInfo{name: "nested", mode: os.ModeDir | 0755}},
+						{filepath.Join(root, "nested/b"), fakeFileInfo{name: "b", size: 2, mode: 0644}},
+					}
+					for _, entry := range entries {
+						if err := walkFn(entry.path, entry.info, nil); err != nil {
+							return err
+						}
+					}
+					return nil
+				}
+				fileThing.open = func(path string) (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader(contents[path])), nil
+				}
+			})
+
+			It("writes an entry for each file and directory", func() {
+				Expect(zipEntries(archive.Bytes())).To(Equal(map[string]string{
+					"a":        "aaa",
+					"nested/":  "",
+					"nested/b": "bb",
+				}))
+			})
+
+			It("closes the archive", func() {
+				Expect(archive.closed).To(BeTrue())
+			})
+
+			Context("and flushing the central directory fails", func() {
+				var removedPath string
+
+				BeforeEach(func() {
+					archive.writeErr = errors.New("I failed")
+					removedPath = ""
+					fileThing.remove = func(path string) error {
+						removedPath = path
+						return nil
+					}
+				})
+
+				It("reports the correct error", func() {
+					Expect(zipErr).To(MatchError("zip " + someSrc + ": I failed"))
+				})
+
+				It("removes the partial archive", func() {
+					Expect(removedPath).To(Equal(someDest))
+				})
+			})
+
+			Context("and reading a file fails", func() {
+				BeforeEach(func() {
+					fileThing.open = func(path string) (io.ReadCloser, error) {
+						if strings.HasSuffix(path, "b") {
+							return ioutil.NopCloser(failingReader{}), nil
+						}
+						return ioutil.NopCloser(strings.NewReader(contents[path])), nil
+					}
+				})
+
+				It("reports the offending path", func() {
+					Expect(zipErr).To(MatchError("zip " + filepath.Join(someSrc, "nested/b") + ": I failed"))
+				})
+			})
+
+			Context("and closing the archive fails", func() {
+				BeforeEach(func() {
+					archive.closeErr = errors.New("I failed")
+				})
+
+				It("reports the correct error", func() {
+					Expect(zipErr).To(MatchError("zip " + someSrc + ": I failed"))
+				})
+			})
+		})
+
+		Context("when creating the archive fails", func() {
+			BeforeEach(func() {
+				fileThing.create = failToCreate
+			})
+
+			It("reports the correct error", func() {
+				Expect(zipErr).To(MatchError("I failed"))
+			})
+		})
+	})
+
+	Describe("#Unzip", func() {
+		var (
+			destDir  string
+			unzipErr error
+		)
+
+		BeforeEach(func() {
+			destDir = filepath.Join(someDir, "dest")
+			_, err := fileThing.Zip(someDest)
+			Expect(err).NotTo(HaveOccurred())
+			fileThing = New(someDest)
+		})
+
+		JustBeforeEach(func() {
+			unzipErr = fileThing.Unzip(destDir)
+		})
+
+		It("does not return an error", func() {
+			Expect(unzipErr).NotTo(HaveOccurred())
+		})
+
+		It("round trips the archived tree", func() {
+			Expect(ioutil.ReadFile(filepath.Join(destDir, "file"))).To(Equal([]byte("some contents")))
+			Expect(ioutil.ReadFile(filepath.Join(destDir, "sub", "other"))).To(Equal([]byte("other")))
+		})
+
+		Context("when the archive is crafted", func() {
+			var (
+				entries  map[string]string
+				names    []string
+				madeDirs []string
+				created  []string
+			)
+
+			BeforeEach(func() {
+				madeDirs, created = nil, nil
+				names = []string{"nested/", "nested/file"}
+				entries = map[string]string{"nested/": "", "nested/file": "benign"}
+
+				fileThing.openSeeker = func(string) (io.ReadSeekCloser, error) {
+					return readSeekNopCloser{bytes.NewReader(zipArchive(names, entries))}, nil
+				}
+				fileThing.mkdirAll = func(path string, perm os.FileMode) error {
+					madeDirs = append(madeDirs, path)
+					return os.MkdirAll(path, perm)
+				}
+				fileThing.openFile = func(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+					created = append(created, name)
+					return os.OpenFile(name, flag, perm)
+				}
+			})
+
+			It("creates directories in the destination", func() {
+				Expect(madeDirs).To(ContainElement(filepath.Join(destDir, "nested")))
+			})
+
+			It("extracts files into the destination", func() {
+				Expect(created).To(Equal([]string{filepath.Join(destDir, "nested", "file")}))
+				Expect(ioutil.ReadFile(filepath.Join(destDir, "nested", "file"))).To(Equal([]byte("benign")))
+			})
+
+			Context("and an entry escapes the destination", func() {
+				BeforeEach(func() {
+					names = append(names, "../evil")
+					entries["../evil"] = "malicious"
+				})
+
+				It("refuses the entry", func() {
+					Expect(unzipErr).To(MatchError(ContainSubstring(`illegal path "../evil" in archive: path traversal`)))
+				})
+
+				It("does not write outside the destination", func() {
+					Expect(created).NotTo(ContainElement(filepath.Join(someDir, "evil")))
+					Expect(filepath.Join(someDir, "evil")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			Context("and chained symlinks lead out of the destination", func() {
+				BeforeEach(func() {
+					archive := zipArchiveWithLinks(
+						[]string{"a", "a/b", "b/evil"},
+						map[string]string{"a": ".", "a/b": "..", "b/evil": "malicious"},
+						map[string]bool{"a": true, "a/b": true},
+					)
+					fileThing.openSeeker = func(string) (io.ReadSeekCloser, error) {
+						return readSeekNopCloser{bytes.NewReader(archive)}, nil
+					}
+				})
+
+				It("rejects the escaping link", func() {
+					Expect(unzipErr).To(MatchError(ContainSubstring(`illegal link target ".." in archive`)))
+				})
+
+				It("does not write outside the destination", func() {
+					Expect(created).NotTo(ContainElement(filepath.Join(someDir, "evil")))
+					Expect(filepath.Join(someDir, "evil")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			Context("and an entry would be written through a symlink out of the destination", func() {
+				BeforeEach(func() {
+					Expect(os.MkdirAll(destDir, 0755)).To(Succeed())
+					Expect(os.Symlink(someDir, filepath.Join(destDir, "out"))).To(Succeed())
+					names = append(names, "out/evil")
+					entries["out/evil"] = "malicious"
+				})
+
+				It("rejects the entry", func() {
+					Expect(unzipErr).To(MatchError(ContainSubstring(`escapes destination through a symlink`)))
+				})
+
+				It("does not write outside the destination", func() {
+					Expect(created).NotTo(ContainElement(filepath.Join(destDir, "out", "evil")))
+					Expect(filepath.Join(someDir, "evil")).NotTo(BeAnExistingFile())
+				})
+			})
+
+			Context("and creating a file fails", func() {
+				BeforeEach(func() {
+					fileThing.openFile = failToOpenFile
+				})
+
+				It("reports the offending path", func() {
+					Expect(unzipErr).To(MatchError("unzip " + filepath.Join(destDir, "nested", "file") + ": I failed"))
+				})
+			})
+		})
+
+		Context("when FileThing.Path is not a zip archive", func() {
+			BeforeEach(func() {
+				fileThing.openSeeker = openSeekableString("not a zip archive")
+			})
+
+			It("reports the correct error", func() {
+				Expect(unzipErr).To(MatchError(ContainSubstring("unzip " + someDest + ": ")))
+			})
+		})
+
+		Context("when opening FileThing.Path fails", func() {
+			BeforeEach(func() {
+				fileThing.openSeeker = failToOpenSeeker
+			})
+
+			It("reports the correct error", func() {
+				Expect(unzipErr).To(MatchError("I failed"))
+			})
+		})
+	})
+})
+
+func zipEntries(data []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	Expect(err).NotTo(HaveOccurred())
+
+	entries := map[string]string{}
+	for _, file := range reader.File {
+		entry, err := file.Open()
+		Expect(err).NotTo(HaveOccurred())
+		contents, err := ioutil.ReadAll(entry)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entry.Close()).To(Succeed())
+		entries[file.Name] = string(contents)
+	}
+	return entries
+}
+
+func zipArchive(names []string, entries map[string]string) []byte {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, name := range names {
+		entry, err := writer.Create(name)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = entry.Write([]byte(entries[name]))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+	return buffer.Bytes()
+}
+
+func zipArchiveWithLinks(names []string, entries map[string]string, links map[string]bool) []byte {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, name := range names {
+		header := &zip.FileHeader{Name: name, Method: zip.Store}
+		header.SetMode(0644)
+		if links[name] {
+			header.SetMode(os.ModeSymlink | 0777)
+		}
+		entry, err := writer.CreateHeader(header)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = entry.Write([]byte(entries[name]))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+	return buffer.Bytes()
+}